@@ -0,0 +1,682 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/davecgh/go-spew/spew"
+
+	"XChenLabs/solana-nft-demo/pkg/metadata"
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// globalFlags are accepted by every subcommand.
+type globalFlags struct {
+	configPath string
+	url        string
+	keypair    string
+
+	// cfg is the configuration after flag overrides, set by parse.
+	cfg config
+	// cfgPath is the config file cfg was read from, which may not exist.
+	cfgPath string
+}
+
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.configPath, "config", "", "config file (default $SOLANA_NFT_CONFIG or ./"+defaultConfigPath+")")
+	fs.StringVar(&g.url, "url", "", "RPC endpoint URL or cluster moniker (devnet, testnet, mainnet-beta, localhost), overrides rpc_endpoint")
+	fs.StringVar(&g.keypair, "keypair", "", "fee payer keypair file (solana-keygen JSON), overrides fee_payer_keypair")
+}
+
+// parse parses args and loads the configuration, letting flags win over it.
+func (g *globalFlags) parse(fs *flag.FlagSet, args []string) error {
+	fs.Parse(args)
+
+	path, explicit := g.configPath, g.configPath != ""
+	if !explicit {
+		path, explicit = os.LookupEnv("SOLANA_NFT_CONFIG")
+		if !explicit {
+			path = defaultConfigPath
+		}
+	}
+
+	cfg, err := loadConfig(path, explicit)
+	if err != nil {
+		return err
+	}
+	if g.url != "" {
+		cfg.RPCEndpoint = g.url
+	}
+	if g.keypair != "" {
+		cfg.FeePayerKeypair = expandHome(g.keypair)
+	}
+	g.cfg, g.cfgPath = cfg, path
+	return nil
+}
+
+func (g *globalFlags) endpoint() string {
+	switch g.cfg.RPCEndpoint {
+	case "devnet", "d":
+		return rpc.DevnetRPCEndpoint
+	case "testnet", "t":
+		return rpc.TestnetRPCEndpoint
+	case "mainnet-beta", "mainnet", "m":
+		return rpc.MainnetRPCEndpoint
+	case "localhost", "l":
+		return rpc.LocalnetRPCEndpoint
+	default:
+		return g.cfg.RPCEndpoint
+	}
+}
+
+// wsEndpoint is ws_endpoint, or else the RPC endpoint with a websocket
+// scheme. A local endpoint naming a port gets the next port, as
+// solana-test-validator serves websockets one port above RPC; any other
+// keeps its port. It is empty when confirmations are polled.
+func (g *globalFlags) wsEndpoint() string {
+	switch g.cfg.WSEndpoint {
+	case "off":
+		return ""
+	case "":
+	default:
+		return g.cfg.WSEndpoint
+	}
+
+	u, err := url.Parse(g.endpoint())
+	if err != nil {
+		return ""
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return ""
+	}
+	if port, err := strconv.Atoi(u.Port()); err == nil && isLoopback(u.Hostname()) {
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port+1))
+	}
+	return u.String()
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (g *globalFlags) client() *client.Client {
+	return client.NewClient(g.endpoint())
+}
+
+func (g *globalFlags) feePayer() (types.Account, error) {
+	feePayer, err := loadKeypair(g.cfg.FeePayerKeypair)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("failed to load fee payer keypair: %w", err)
+	}
+	return feePayer, nil
+}
+
+// minter loads the fee payer keypair and returns a Minter paying from it.
+func (g *globalFlags) minter() (*nft.Minter, error) {
+	feePayer, err := g.feePayer()
+	if err != nil {
+		return nil, err
+	}
+	return g.screenedMinter(feePayer)
+}
+
+// screenedMinter returns a Minter paying from feePayer that screens
+// recipients against the deny_list.
+func (g *globalFlags) screenedMinter(feePayer types.Account) (*nft.Minter, error) {
+	m := g.newMinter(feePayer)
+	var err error
+	m.Screening, err = g.screening()
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// screening returns the recipient screening of transfers and swaps, nil
+// without a deny_list.
+func (g *globalFlags) screening() (*nft.ScreeningHook, error) {
+	if g.cfg.DenyList == "" {
+		return nil, nil
+	}
+	denyList, err := nft.LoadDenyList(g.cfg.DenyList, nft.ScreeningBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deny_list: %w", err)
+	}
+	var audit io.Writer
+	if g.cfg.ScreeningLog != "" {
+		f, err := os.OpenFile(g.cfg.ScreeningLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open screening_log: %w", err)
+		}
+		audit = f
+	}
+	return nft.NewScreeningHook(audit, denyList), nil
+}
+
+// readOnlyMinter returns a Minter for commands that never send transactions.
+func (g *globalFlags) readOnlyMinter() *nft.Minter {
+	return g.newMinter(types.Account{})
+}
+
+func (g *globalFlags) newMinter(feePayer types.Account) *nft.Minter {
+	m := nft.NewMinter(g.client(), feePayer)
+	m.Commitment = rpc.Commitment(g.cfg.Commitment)
+	m.TxVersion = types.MessageVersion(g.cfg.TxVersion)
+	m.WSEndpoint = g.wsEndpoint()
+	dasEndpoint := g.cfg.DASEndpoint
+	if dasEndpoint == "" {
+		dasEndpoint = g.endpoint()
+	}
+	m.DAS = nft.NewDASClient(dasEndpoint)
+	if g.cfg.HistoryFile != "" {
+		m.History = nft.NewMetadataHistory(g.cfg.HistoryFile)
+	}
+	return m
+}
+
+// uploader returns the storage provider of upload-metadata.
+func (g *globalFlags) uploader() (metadata.Uploader, error) {
+	switch g.cfg.Storage {
+	case "pinata":
+		if g.cfg.PinataAPIKey == "" || g.cfg.PinataAPISecret == "" {
+			return nil, errors.New("pinata_api_key and pinata_api_secret are required to upload to pinata")
+		}
+		return metadata.NewPinataUploader(g.cfg.PinataAPIKey, g.cfg.PinataAPISecret), nil
+	case "nft.storage", "web3.storage":
+		if g.cfg.StorageToken == "" {
+			return nil, fmt.Errorf("storage_token is required to upload to %v", g.cfg.Storage)
+		}
+		endpoint := metadata.NFTStorageEndpoint
+		if g.cfg.Storage == "web3.storage" {
+			endpoint = metadata.Web3StorageEndpoint
+		}
+		return metadata.NewNFTStorageUploader(endpoint, g.cfg.StorageToken), nil
+	default:
+		feePayer, err := g.feePayer()
+		if err != nil {
+			return nil, err
+		}
+		node := g.cfg.IrysNode
+		if node == "" {
+			node = metadata.IrysMainnetNode
+			if g.endpoint() == rpc.DevnetRPCEndpoint {
+				node = metadata.IrysDevnetNode
+			}
+		}
+		uploader := metadata.NewIrysUploader(node, g.client(), feePayer)
+		uploader.TxVersion = types.MessageVersion(g.cfg.TxVersion)
+		if uploader.Expires() {
+			log.Printf("warning: uploads to the Irys devnet node %v are deleted after about %v days, use a mainnet node for NFTs that must last", node, metadata.IrysDevnetRetention.Hours()/24)
+		}
+		return uploader, nil
+	}
+}
+
+func defaultKeypairPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "solana", "id.json")
+}
+
+// loadKeypair reads a keypair file as written by solana-keygen: a JSON array
+// of the 64 secret key bytes.
+func loadKeypair(path string) (types.Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return types.Account{}, err
+	}
+	var key []byte
+	if err := json.Unmarshal(data, &key); err != nil {
+		return types.Account{}, fmt.Errorf("%v is not a keypair file: %w", path, err)
+	}
+	return types.AccountFromBytes(key)
+}
+
+// pubkeyFlag is a flag.Value holding a validated base58 public key.
+type pubkeyFlag struct {
+	key common.PublicKey
+	set bool
+}
+
+func (p *pubkeyFlag) String() string {
+	if !p.set {
+		return ""
+	}
+	return p.key.ToBase58()
+}
+
+func (p *pubkeyFlag) Set(s string) error {
+	key, err := nft.ParsePublicKey(s)
+	if err != nil {
+		return err
+	}
+	p.key, p.set = key, true
+	return nil
+}
+
+// creatorsFlag is a repeatable flag.Value collecting ADDRESS:SHARE[:verified]
+// creator entries.
+type creatorsFlag []token_metadata.Creator
+
+func (c *creatorsFlag) String() string {
+	var entries []string
+	for _, creator := range *c {
+		entry := fmt.Sprintf("%v:%v", creator.Address.ToBase58(), creator.Share)
+		if creator.Verified {
+			entry += ":verified"
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+func (c *creatorsFlag) Set(s string) error {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "verified") {
+		return fmt.Errorf("invalid creator %q, want ADDRESS:SHARE[:verified]", s)
+	}
+	address, err := nft.ParsePublicKey(parts[0])
+	if err != nil {
+		return err
+	}
+	share, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil || share > 100 {
+		return fmt.Errorf("invalid creator share %q, want 0 to 100", parts[1])
+	}
+	*c = append(*c, token_metadata.Creator{
+		Address:  address,
+		Share:    uint8(share),
+		Verified: len(parts) == 3,
+	})
+	return nil
+}
+
+// hookAccountsFlag is a repeatable flag.Value collecting ADDRESS[:writable]
+// extra accounts of a transfer hook.
+type hookAccountsFlag []types.AccountMeta
+
+func (h *hookAccountsFlag) String() string {
+	var entries []string
+	for _, account := range *h {
+		entry := account.PubKey.ToBase58()
+		if account.IsWritable {
+			entry += ":writable"
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+func (h *hookAccountsFlag) Set(s string) error {
+	address, writable, _ := strings.Cut(s, ":")
+	if writable != "" && writable != "writable" {
+		return fmt.Errorf("invalid transfer hook account %q, want ADDRESS[:writable]", s)
+	}
+	key, err := nft.ParsePublicKey(address)
+	if err != nil {
+		return err
+	}
+	*h = append(*h, types.AccountMeta{PubKey: key, IsWritable: writable != ""})
+	return nil
+}
+
+// sellerFeeFlag registers the royalty flag shared by the mint commands.
+func sellerFeeFlag(fs *flag.FlagSet) *uint {
+	return fs.Uint("seller-fee-bps", 0, "secondary sale royalty in basis points, 500 being 5%")
+}
+
+// sellerFeeBasisPoints checks the -seller-fee-bps value fits the metadata.
+func sellerFeeBasisPoints(bps uint) (uint16, error) {
+	if bps > 10000 {
+		return 0, fmt.Errorf("-seller-fee-bps %v is over 10000", bps)
+	}
+	return uint16(bps), nil
+}
+
+// attributesFlag is a repeatable flag.Value collecting TRAIT=VALUE metadata
+// attributes; numeric values are kept as numbers.
+type attributesFlag []metadata.Attribute
+
+func (a *attributesFlag) String() string {
+	var entries []string
+	for _, attribute := range *a {
+		entries = append(entries, fmt.Sprintf("%v=%v", attribute.TraitType, attribute.Value))
+	}
+	return strings.Join(entries, ",")
+}
+
+func (a *attributesFlag) Set(s string) error {
+	trait, value, ok := strings.Cut(s, "=")
+	if !ok || trait == "" {
+		return fmt.Errorf("invalid attribute %q, want TRAIT=VALUE", s)
+	}
+	attribute := metadata.Attribute{TraitType: trait, Value: value}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		attribute.Value = number
+	}
+	*a = append(*a, attribute)
+	return nil
+}
+
+// truncateNamesFlag registers the long name flag shared by the mint commands.
+func truncateNamesFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("truncate-names", false, fmt.Sprintf("truncate names over %v bytes and symbols over %v bytes instead of failing", nft.MaxNameLength, nft.MaxSymbolLength))
+}
+
+// lengthPolicy maps -truncate-names to a policy, warning when it cuts name
+// or symbol.
+func lengthPolicy(truncate bool, name, symbol string) nft.LengthPolicy {
+	if !truncate {
+		return nft.RejectLongNames
+	}
+	if len(name) > nft.MaxNameLength || len(symbol) > nft.MaxSymbolLength {
+		log.Printf("warning: name %q or symbol %q is truncated on-chain, the full name stays in the off-chain metadata", name, symbol)
+	}
+	return nft.TruncateLongNames
+}
+
+// checkMetadataFlag registers the off-chain metadata check shared by the mint
+// commands.
+func checkMetadataFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("check-metadata", true, "fetch the metadata JSON at the uri and refuse to mint when it is invalid")
+}
+
+// checkMetadata validates the metadata an NFT is about to be minted with.
+// A URI template only resolves once minted and is not checked.
+func checkMetadata(uri string, names nft.LengthPolicy) error {
+	if strings.Contains(uri, "{mint}") {
+		return nil
+	}
+	if err := metadata.Check(context.Background(), uri, names); err != nil {
+		return fmt.Errorf("%w\n(-check-metadata=false mints anyway)", err)
+	}
+	return nil
+}
+
+// planFlags let a sending command write an nft.Plan for review instead.
+type planFlags struct {
+	out       string
+	blockhash string
+	feePayer  pubkeyFlag
+}
+
+func (p *planFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&p.out, "plan", "", "write the unsigned transaction to this file for review instead of sending it, - for stdout")
+	fs.StringVar(&p.blockhash, "blockhash", "", "recent blockhash of the plan, reviewers pass the one it was built with (default: the latest)")
+	fs.Var(&p.feePayer, "fee-payer", "fee payer public key of the plan (default: from the fee payer keypair)")
+}
+
+func (p *planFlags) enabled() bool {
+	return p.out != ""
+}
+
+// minter returns a Minter for planning, which needs no secret key when
+// -fee-payer is given.
+func (p *planFlags) minter(g *globalFlags) (*nft.Minter, error) {
+	if p.feePayer.set {
+		return g.screenedMinter(types.Account{PublicKey: p.feePayer.key})
+	}
+	return g.minter()
+}
+
+func (p *planFlags) recentBlockhash(m *nft.Minter) (string, error) {
+	if p.blockhash != "" {
+		return p.blockhash, nil
+	}
+	res, err := m.Client().GetLatestBlockhashWithConfig(context.Background(), client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+	return res.Blockhash, nil
+}
+
+func (p *planFlags) write(plan *nft.Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	data = append(data, '\n')
+
+	if p.out == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(p.out, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	fmt.Printf("plan written to %v\nblockhash: %v\nmessage sha256: %v\nsigners: %v\n", p.out, plan.RecentBlockhash, plan.MessageSHA256, strings.Join(plan.Signers, ", "))
+	return nil
+}
+
+// requireFlags fails when any of the named flags was not given.
+func requireFlags(fs *flag.FlagSet, names ...string) error {
+	seen := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { seen[f.Name] = true })
+
+	var missing []string
+	for _, name := range names {
+		if !seen[name] {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.New("missing required flags: " + strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func waitForTxConfirmation(m *nft.Minter, txHash string) {
+	fmt.Println("waiting for tx", txHash, "confirmation...")
+	if err := m.WaitForConfirmation(context.Background(), txHash, nft.ConfirmOptions{}); err != nil {
+		log.Fatalf("failed to confirm tx, err: %v", err)
+	}
+	fmt.Printf("Transaction successfully confirmed!\n\n")
+}
+
+func waitForTxFinalized(m *nft.Minter, txHash string) {
+	fmt.Println("waiting for tx", txHash, "to be finalized...")
+	finalized, err := m.WaitFinalized(context.Background(), txHash, nft.ConfirmOptions{})
+	if err != nil {
+		log.Fatalf("failed to finalize tx, err: %v", err)
+	}
+	fmt.Printf("Transaction finalized in slot %v", finalized.Slot)
+	if finalized.BlockTime != nil {
+		fmt.Printf(" at %v", finalized.BlockTime.Format(time.RFC3339))
+	}
+	fmt.Printf("\n\n")
+}
+
+// printNFTInfo prints the on-chain state of the NFT held in ata and, with
+// offChain, the metadata JSON its URI points at.
+func printNFTInfo(m *nft.Minter, ata common.PublicKey, offChain bool) {
+
+	fmt.Println("token info for:", ata.ToBase58(), "-------------------------------------------")
+
+	info, err := m.GetInfo(context.Background(), ata)
+	if err != nil {
+		log.Fatalf("failed to get nft info, err: %v", err)
+	}
+
+	fmt.Printf("token account:\n%+v\n\n", info.TokenAccount)
+	fmt.Printf("mint account:\n%+v\n\n", info.Mint)
+
+	if info.Extensions != nil {
+		fmt.Printf("token-2022 extensions:\n%+v\n\n", *info.Extensions)
+		for _, warning := range info.Extensions.Warnings() {
+			fmt.Printf("WARNING: %v\n\n", warning)
+		}
+	}
+
+	if info.Metadata != nil {
+		fmt.Println("metadata account:")
+		spew.Dump(*info.Metadata)
+	} else if info.Extensions != nil && info.Extensions.TokenMetadata != nil {
+		fmt.Println("token metadata extension:")
+		spew.Dump(*info.Extensions.TokenMetadata)
+	}
+
+	if offChain && info.URI() != "" {
+		printOffChainMetadata(info.URI())
+	}
+
+	fmt.Println("---------------------------------------------------------------------")
+}
+
+// printAssetInfo prints the DAS view of an asset, compressed or not.
+func printAssetInfo(m *nft.Minter, id common.PublicKey, offChain bool) error {
+	asset, err := m.DAS.GetAsset(context.Background(), id.ToBase58())
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("asset info for:", asset.ID, "-------------------------------------------")
+	fmt.Printf("interface: %v\nname: %q\nsymbol: %q\nuri: %v\n", asset.Interface, asset.Content.Metadata.Name, asset.Content.Metadata.Symbol, asset.Content.JSONURI)
+	fmt.Printf("owner: %v\nmutable: %v\nburnt: %v\n", asset.Ownership.Owner, asset.Mutable, asset.Burnt)
+	if asset.Ownership.Delegated {
+		fmt.Printf("delegate: %v\n", asset.Ownership.Delegate)
+	}
+	if asset.Compression.Compressed {
+		fmt.Printf("compressed in tree %v, leaf %v\n", asset.Compression.Tree, asset.Compression.LeafID)
+	}
+
+	if offChain && asset.Content.JSONURI != "" {
+		printOffChainMetadata(asset.Content.JSONURI)
+	}
+
+	fmt.Println("---------------------------------------------------------------------")
+	return nil
+}
+
+// printOffChainMetadata prints the name, image and attributes of the
+// metadata JSON at uri, or why it could not be read.
+func printOffChainMetadata(uri string) {
+	fmt.Printf("\noff-chain metadata (%v):\n", uri)
+	md, err := metadata.Fetch(context.Background(), uri)
+	if err != nil {
+		fmt.Printf("  unavailable: %v\n\n", err)
+		return
+	}
+	fmt.Printf("  name: %v\n", md.Name)
+	if md.Description != "" {
+		fmt.Printf("  description: %v\n", md.Description)
+	}
+	if md.Image != "" {
+		fmt.Printf("  image: %v\n", nft.GatewayURL(md.Image))
+	}
+	for _, attribute := range md.Attributes {
+		fmt.Printf("  %v: %v\n", attribute.TraitType, attribute.Value)
+	}
+	fmt.Println()
+}
+
+// readTransaction reads a base64 serialized transaction from path, or from
+// stdin when path is "-".
+func readTransaction(path string) (types.Transaction, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("failed to read transaction: %w", err)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("transaction is not base64: %w", err)
+	}
+	tx, err := types.TransactionDeserialize(raw)
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// writeTransaction writes tx base64 serialized to path, or to stdout when
+// path is "-".
+func writeTransaction(path string, tx *types.Transaction) error {
+	raw, err := tx.Serialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(raw) + "\n"
+	if path == "-" {
+		_, err = os.Stdout.WriteString(encoded)
+	} else {
+		err = os.WriteFile(path, []byte(encoded), 0o644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write transaction: %w", err)
+	}
+	return nil
+}
+
+func printSimulationReport(report *nft.SimulationReport) {
+	if report.Err != "" {
+		fmt.Printf("result: FAILED %v\n", report.Err)
+	} else {
+		fmt.Println("result: ok")
+	}
+	fmt.Printf("compute units: %v\n\n", report.UnitsConsumed)
+
+	for _, ix := range report.Instructions {
+		fmt.Printf("instruction %v: %v, %v compute units, %v inner calls\n", ix.Index, ix.ProgramID, ix.UnitsConsumed, ix.InnerInvocations)
+		if ix.Error != "" {
+			fmt.Printf("  error: %v\n", ix.Error)
+		}
+		for _, line := range ix.Logs {
+			fmt.Printf("  %v\n", line)
+		}
+		fmt.Println()
+	}
+
+	if len(report.AccountWrites) > 0 {
+		fmt.Println("account writes:")
+		for _, write := range report.AccountWrites {
+			fmt.Printf("  %v: lamports %v -> %v, data %v -> %v bytes", write.Address, write.LamportsBefore, write.LamportsAfter, write.DataLenBefore, write.DataLenAfter)
+			if write.OwnerBefore != write.OwnerAfter {
+				fmt.Printf(", owner %v -> %v", write.OwnerBefore, write.OwnerAfter)
+			}
+			if write.DataChanged {
+				fmt.Print(", data changed")
+			}
+			fmt.Println()
+		}
+		fmt.Println()
+	}
+
+	if report.ReturnData != nil {
+		fmt.Printf("return data from %v: %x\n", report.ReturnData.ProgramID, report.ReturnData.Data)
+	}
+}
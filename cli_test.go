@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/token"
+
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+func TestWSEndpoint(t *testing.T) {
+	tests := []struct {
+		rpc, ws string
+		want    string
+	}{
+		{rpc: "localhost", want: "ws://localhost:8900"},
+		{rpc: "http://localhost:8899", want: "ws://localhost:8900"},
+		{rpc: "http://127.0.0.1:8899", want: "ws://127.0.0.1:8900"},
+		{rpc: "http://[::1]:8899", want: "ws://[::1]:8900"},
+		{rpc: "devnet", want: "wss://api.devnet.solana.com"},
+		{rpc: "https://rpc.example.com:8443/key", want: "wss://rpc.example.com:8443/key"},
+		{rpc: "http://10.0.0.5:8899", want: "ws://10.0.0.5:8899"},
+		{rpc: "devnet", ws: "wss://ws.example.com", want: "wss://ws.example.com"},
+		{rpc: "devnet", ws: "off", want: ""},
+		{rpc: "unix:///tmp/rpc", want: ""},
+	}
+	for _, tt := range tests {
+		g := globalFlags{cfg: config{RPCEndpoint: tt.rpc, WSEndpoint: tt.ws}}
+		if got := g.wsEndpoint(); got != tt.want {
+			t.Errorf("wsEndpoint(%q, %q) = %q, want %q", tt.rpc, tt.ws, got, tt.want)
+		}
+	}
+}
+
+// tokenAccountServer answers every RPC call with a token account of owner
+// holding one token of mint.
+func tokenAccountServer(t *testing.T, mint, owner common.PublicKey) *httptest.Server {
+	t.Helper()
+	data := make([]byte, token.TokenAccountSize)
+	copy(data, mint.Bytes())
+	copy(data[32:], owner.Bytes())
+	binary.LittleEndian.PutUint64(data[64:], 1)
+	data[108] = 1 // initialized
+
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"result": map[string]any{
+			"context": map[string]any{"slot": 1},
+			"value": map[string]any{
+				"data":       []string{base64.StdEncoding.EncodeToString(data), "base64"},
+				"executable": false,
+				"lamports":   2039280,
+				"owner":      common.TokenProgramID.ToBase58(),
+				"rentEpoch":  0,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTransferPlanScreening(t *testing.T) {
+	sender := common.PublicKeyFromString(testCreator)
+	server := tokenAccountServer(t, common.PublicKeyFromString(testCreator), sender)
+
+	dir := t.TempDir()
+	denyList := filepath.Join(dir, "deny.txt")
+	if err := os.WriteFile(denyList, []byte(testReceiver+" sanctioned\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(config, []byte("deny_list: "+denyList+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a plan is built from public keys only, and is screened all the same
+	err := runTransfer([]string{
+		"-config", config,
+		"-url", server.URL,
+		"-token", testReceiver,
+		"-receiver", testReceiver,
+		"-sender", testCreator,
+		"-fee-payer", testCreator,
+		"-blockhash", "11111111111111111111111111111111",
+		"-plan", filepath.Join(dir, "plan.json"),
+	})
+	if !errors.Is(err, nft.ErrRecipientBlocked) {
+		t.Fatalf("got error %v, want %v", err, nft.ErrRecipientBlocked)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plan.json")); !os.IsNotExist(err) {
+		t.Errorf("a plan was written for a blocked recipient: %v", err)
+	}
+}
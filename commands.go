@@ -0,0 +1,1433 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/types"
+
+	"XChenLabs/solana-nft-demo/pkg/metadata"
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+func runUploadMetadata(args []string) error {
+	var g globalFlags
+	var creators creatorsFlag
+	var attributes attributesFlag
+	fs := flag.NewFlagSet("upload-metadata", flag.ExitOnError)
+	g.register(fs)
+	name := fs.String("name", "", "NFT name")
+	symbol := fs.String("symbol", "", "NFT symbol")
+	description := fs.String("description", "", "NFT description")
+	image := fs.String("image", "", "image file uploaded with the metadata")
+	externalURL := fs.String("external-url", "", "web page of the NFT")
+	fs.Var(&attributes, "attribute", "trait as TRAIT=VALUE, repeatable")
+	fs.Var(&creators, "creator", "royalty creator as ADDRESS:SHARE[:verified], repeatable")
+	sellerFee := sellerFeeFlag(fs)
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "name", "image"); err != nil {
+		return err
+	}
+	sellerFeeBps, err := sellerFeeBasisPoints(*sellerFee)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*image)
+	if err != nil {
+		return fmt.Errorf("failed to read image: %w", err)
+	}
+	uploader, err := g.uploader()
+	if err != nil {
+		return err
+	}
+
+	md := metadata.Metadata{
+		Name:                 *name,
+		Symbol:               *symbol,
+		Description:          *description,
+		SellerFeeBasisPoints: sellerFeeBps,
+		ExternalURL:          *externalURL,
+		Attributes:           attributes,
+	}
+	for _, creator := range creators {
+		md.Properties.Creators = append(md.Properties.Creators, metadata.Creator{Address: creator.Address.ToBase58(), Share: creator.Share})
+	}
+
+	uri, err := metadata.Publish(context.Background(), uploader, md, metadata.Asset{Name: filepath.Base(*image), Data: data})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("uri: %v\n", uri)
+	return nil
+}
+
+func runMint(args []string) error {
+	var g globalFlags
+	var receiver, collection, ruleSet, transferHook pubkeyFlag
+	var creators creatorsFlag
+	var hookAccounts hookAccountsFlag
+	fs := flag.NewFlagSet("mint", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&receiver, "receiver", "wallet receiving the NFT")
+	name := fs.String("name", "", "NFT name")
+	symbol := fs.String("symbol", "", "NFT symbol")
+	truncate := truncateNamesFlag(fs)
+	uri := fs.String("uri", "", "off-chain metadata URI")
+	fs.Var(&collection, "collection", "collection the NFT belongs to (default: default_collection)")
+	fs.Var(&creators, "creator", "royalty creator as ADDRESS:SHARE[:verified], repeatable")
+	sellerFee := sellerFeeFlag(fs)
+	mutable := fs.Bool("mutable", false, "allow the metadata to be updated later")
+	maxEditions := fs.Uint64("max-editions", 0, "number of numbered editions print-edition may print")
+	unlimitedEditions := fs.Bool("unlimited-editions", false, "allow printing any number of editions")
+	verify := fs.Bool("verify-collection", true, "verify the NFT as a collection member, the fee payer must be the collection authority")
+	offCurve := fs.Bool("allow-owner-off-curve", false, "allow a PDA receiver")
+	programmable := fs.Bool("programmable", false, "mint a programmable NFT")
+	fs.Var(&ruleSet, "rule-set", "authorization rule set of the programmable NFT")
+	token2022 := fs.Bool("token-2022", false, "mint under Token-2022 with the metadata held in the mint")
+	check := checkMetadataFlag(fs)
+	fs.Var(&transferHook, "transfer-hook", "program Token-2022 invokes on every transfer, e.g. for royalties (token-2022 only)")
+	fs.Var(&hookAccounts, "transfer-hook-account", "extra account of the transfer hook as ADDRESS[:writable], repeatable")
+	soulbound := fs.Bool("soulbound", false, "mint a non-transferable Token-2022 NFT, e.g. a badge or credential")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "receiver", "name", "uri"); err != nil {
+		return err
+	}
+	sellerFeeBps, err := sellerFeeBasisPoints(*sellerFee)
+	if err != nil {
+		return err
+	}
+
+	// the Token-2022 metadata extension has no name or symbol limits
+	names := nft.TruncateLongNames
+	if !*token2022 && !*soulbound {
+		names = lengthPolicy(*truncate, *name, *symbol)
+	}
+	if *check {
+		if err := checkMetadata(*uri, names); err != nil {
+			return err
+		}
+	}
+
+	// Token-2022 NFTs have no collection to default to
+	if !collection.set && g.cfg.DefaultCollection != "" && !*token2022 && !*soulbound {
+		collection.Set(g.cfg.DefaultCollection)
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	minted, err := m.Mint(context.Background(), nft.MintRequest{
+		Receiver:             receiver.key,
+		Name:                 *name,
+		Symbol:               *symbol,
+		URI:                  *uri,
+		LongNames:            names,
+		Collection:           collection.key,
+		Creators:             creators,
+		SellerFeeBasisPoints: sellerFeeBps,
+		Mutable:              *mutable,
+		MaxEditions:          *maxEditions,
+		UnlimitedEditions:    *unlimitedEditions,
+		VerifyCollection:     *verify,
+		AllowOwnerOffCurve:   *offCurve,
+		Programmable:         *programmable,
+		RuleSet:              ruleSet.key,
+		Token2022:            *token2022,
+		TransferHook:         transferHook.key,
+		TransferHookAccounts: hookAccounts,
+		Soulbound:            *soulbound,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\nmint: %v\ntoken account: %v\n\n", minted.Signature, minted.Mint.ToBase58(), minted.TokenAccount.ToBase58())
+
+	if *wait {
+		waitForTxConfirmation(m, minted.Signature)
+	}
+	return nil
+}
+
+func runMintCompressed(args []string) error {
+	var g globalFlags
+	var tree, receiver, collection pubkeyFlag
+	var creators creatorsFlag
+	fs := flag.NewFlagSet("mint-compressed", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&tree, "tree", "Bubblegum Merkle tree the NFT is minted into (default: default_tree)")
+	fs.Var(&receiver, "receiver", "wallet receiving the NFT")
+	name := fs.String("name", "", "NFT name")
+	symbol := fs.String("symbol", "", "NFT symbol")
+	truncate := truncateNamesFlag(fs)
+	uri := fs.String("uri", "", "off-chain metadata URI")
+	fs.Var(&collection, "collection", "collection the NFT is verified in (default: default_collection)")
+	fs.Var(&creators, "creator", "royalty creator as ADDRESS:SHARE[:verified], repeatable")
+	sellerFee := sellerFeeFlag(fs)
+	mutable := fs.Bool("mutable", false, "allow the metadata to be updated later")
+	check := checkMetadataFlag(fs)
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if !collection.set && g.cfg.DefaultCollection != "" {
+		collection.Set(g.cfg.DefaultCollection)
+	}
+	if !tree.set && g.cfg.DefaultTree != "" {
+		tree.Set(g.cfg.DefaultTree)
+	}
+	if err := requireFlags(fs, "receiver", "name", "uri"); err != nil {
+		return err
+	}
+	if !tree.set {
+		return fmt.Errorf("-tree is required when default_tree is not configured")
+	}
+	if !collection.set {
+		return fmt.Errorf("-collection is required when default_collection is not configured")
+	}
+	// checked here as checkMetadata skips templates
+	if strings.Contains(*uri, "{mint}") {
+		return nft.ErrCompressedURITemplate
+	}
+	sellerFeeBps, err := sellerFeeBasisPoints(*sellerFee)
+	if err != nil {
+		return err
+	}
+	names := lengthPolicy(*truncate, *name, *symbol)
+	if *check {
+		if err := checkMetadata(*uri, names); err != nil {
+			return err
+		}
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	minted, err := m.MintCompressed(context.Background(), nft.CompressedMintRequest{
+		Tree:                 tree.key,
+		Receiver:             receiver.key,
+		Name:                 *name,
+		Symbol:               *symbol,
+		URI:                  *uri,
+		LongNames:            names,
+		Collection:           collection.key,
+		Creators:             creators,
+		SellerFeeBasisPoints: sellerFeeBps,
+		Mutable:              *mutable,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\nasset id: %v\n\n", minted.Signature, minted.AssetID.ToBase58())
+
+	if *wait {
+		waitForTxConfirmation(m, minted.Signature)
+	}
+	return nil
+}
+
+func runCreateTree(args []string) error {
+	var g globalFlags
+	fs := flag.NewFlagSet("create-tree", flag.ExitOnError)
+	g.register(fs)
+	maxDepth := fs.Uint("max-depth", 14, "tree depth, the tree holds 2^depth NFTs")
+	maxBufferSize := fs.Uint("max-buffer-size", 64, "concurrent changes the tree accepts per slot")
+	canopyDepth := fs.Int("canopy-depth", 0, "top levels cached on chain to shorten transfer proofs")
+	public := fs.Bool("public", false, "let anyone mint into the tree")
+	save := fs.Bool("save", true, "store the tree as default_tree in the config file once confirmed (implies -wait)")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	req := nft.TreeRequest{
+		MaxDepth:      uint32(*maxDepth),
+		MaxBufferSize: uint32(*maxBufferSize),
+		CanopyDepth:   *canopyDepth,
+		Public:        *public,
+	}
+	size, rent, err := m.TreeRent(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("tree account: %v bytes, rent %v lamports (%.9f SOL), capacity %v NFTs\n", size, rent, float64(rent)/1e9, uint64(1)<<*maxDepth)
+
+	created, err := m.CreateTree(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\ntree: %v\n\n", created.Signature, created.Tree.ToBase58())
+
+	// a tree is only saved once it exists, so -save waits too
+	if *wait || *save {
+		waitForTxConfirmation(m, created.Signature)
+	}
+
+	if *save {
+		if err := setConfigValue(g.cfgPath, "default_tree", created.Tree.ToBase58()); err != nil {
+			return err
+		}
+		fmt.Printf("default_tree saved to %v\n", g.cfgPath)
+	}
+	return nil
+}
+
+func runBatchMint(args []string) error {
+	var g globalFlags
+	var collection, ruleSet pubkeyFlag
+	var creators creatorsFlag
+	fs := flag.NewFlagSet("batch-mint", flag.ExitOnError)
+	g.register(fs)
+	manifest := fs.String("manifest", "", "JSON or CSV file listing name, uri and receiver per NFT")
+	fs.Var(&collection, "collection", "collection of entries that name none (default: default_collection)")
+	fs.Var(&creators, "creator", "royalty creator of entries that name none as ADDRESS:SHARE[:verified], repeatable")
+	sellerFee := sellerFeeFlag(fs)
+	mutable := fs.Bool("mutable", false, "allow the metadata to be updated later")
+	verify := fs.Bool("verify-collection", true, "verify the NFTs as collection members, the fee payer must be the collection authority")
+	programmable := fs.Bool("programmable", false, "mint programmable NFTs")
+	fs.Var(&ruleSet, "rule-set", "authorization rule set of the programmable NFTs")
+	truncate := truncateNamesFlag(fs)
+	check := checkMetadataFlag(fs)
+	concurrency := fs.Int("concurrency", 4, "number of mints in flight at once")
+	wait := fs.Bool("wait", true, "wait for each mint to be confirmed")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "manifest"); err != nil {
+		return err
+	}
+	sellerFeeBps, err := sellerFeeBasisPoints(*sellerFee)
+	if err != nil {
+		return err
+	}
+
+	if !collection.set && g.cfg.DefaultCollection != "" {
+		collection.Set(g.cfg.DefaultCollection)
+	}
+
+	entries, err := loadManifest(*manifest)
+	if err != nil {
+		return err
+	}
+	feePayer, err := g.feePayer()
+	if err != nil {
+		return err
+	}
+	reqs, err := mintRequests(entries, manifestDefaults{
+		collection:           collection,
+		creators:             creators,
+		sellerFeeBasisPoints: sellerFeeBps,
+		verify:               *verify,
+		truncate:             *truncate,
+		feePayer:             feePayer.PublicKey,
+	})
+	if err != nil {
+		return err
+	}
+	for i := range reqs {
+		reqs[i].Mutable = *mutable
+		reqs[i].Programmable = *programmable
+		reqs[i].RuleSet = ruleSet.key
+		if *check {
+			if err := checkMetadata(reqs[i].URI, reqs[i].LongNames); err != nil {
+				return fmt.Errorf("entry %v: %w", i+1, err)
+			}
+		}
+	}
+
+	m, err := g.screenedMinter(feePayer)
+	if err != nil {
+		return err
+	}
+
+	items := m.MintBatch(context.Background(), reqs, nft.BatchOptions{
+		Concurrency:         *concurrency,
+		WaitForConfirmation: *wait,
+		Report: func(item nft.BatchItem) {
+			if item.Err != nil {
+				fmt.Printf("[%v/%v] %v: FAILED: %v\n", item.Index+1, len(reqs), item.Request.Name, item.Err)
+				return
+			}
+			fmt.Printf("[%v/%v] %v: minted %v, signature %v\n", item.Index+1, len(reqs), item.Request.Name, item.Result.Mint.ToBase58(), item.Result.Signature)
+		},
+	})
+
+	var failed []nft.BatchItem
+	for _, item := range items {
+		if item.Err != nil {
+			failed = append(failed, item)
+		}
+	}
+	fmt.Printf("\n%v minted, %v failed\n", len(items)-len(failed), len(failed))
+	for _, item := range failed {
+		fmt.Printf("  entry %v (%v): %v\n", item.Index+1, item.Request.Name, item.Err)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%v of %v mints failed", len(failed), len(items))
+	}
+	return nil
+}
+
+func runDeployCandyMachine(args []string) error {
+	var g globalFlags
+	var collection, treasury pubkeyFlag
+	var creators creatorsFlag
+	fs := flag.NewFlagSet("deploy-candy-machine", flag.ExitOnError)
+	g.register(fs)
+	manifest := fs.String("manifest", "", "JSON or CSV file listing the name and uri of each item; receivers are ignored")
+	fs.Var(&collection, "collection", "collection the items are verified in, the fee payer must be its update authority (default: default_collection)")
+	symbol := fs.String("symbol", "", "symbol of the items")
+	fs.Var(&creators, "creator", "royalty creator of every item as ADDRESS:SHARE, repeatable")
+	sellerFee := sellerFeeFlag(fs)
+	mutable := fs.Bool("mutable", false, "allow the metadata of the items to be updated later")
+	price := fs.Uint64("price", 0, "lamports each mint pays to the treasury (solPayment guard)")
+	fs.Var(&treasury, "treasury", "wallet receiving the price (default: the fee payer)")
+	botTax := fs.Uint64("bot-tax", 0, "lamports charged for a mint the guards reject instead of failing it (botTax guard)")
+	start := fs.String("start", "", "RFC 3339 time the mint opens (startDate guard)")
+	end := fs.String("end", "", "RFC 3339 time the mint closes (endDate guard)")
+	mintLimit := fs.Uint("mint-limit", 0, "mints allowed per wallet (mintLimit guard)")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "manifest"); err != nil {
+		return err
+	}
+	sellerFeeBps, err := sellerFeeBasisPoints(*sellerFee)
+	if err != nil {
+		return err
+	}
+	if *mintLimit > math.MaxUint16 {
+		return fmt.Errorf("-mint-limit %v is over %v", *mintLimit, math.MaxUint16)
+	}
+
+	if !collection.set && g.cfg.DefaultCollection != "" {
+		collection.Set(g.cfg.DefaultCollection)
+	}
+	if !collection.set {
+		return fmt.Errorf("-collection is required when default_collection is not configured")
+	}
+
+	req := nft.CandyMachineDeployRequest{
+		Collection:           collection.key,
+		Symbol:               *symbol,
+		Creators:             creators,
+		SellerFeeBasisPoints: sellerFeeBps,
+		Mutable:              *mutable,
+		Price:                *price,
+		Treasury:             treasury.key,
+		BotTax:               *botTax,
+		MintLimit:            uint16(*mintLimit),
+	}
+	if *start != "" {
+		if req.StartDate, err = time.Parse(time.RFC3339, *start); err != nil {
+			return fmt.Errorf("invalid -start: %w", err)
+		}
+	}
+	if *end != "" {
+		if req.EndDate, err = time.Parse(time.RFC3339, *end); err != nil {
+			return fmt.Errorf("invalid -end: %w", err)
+		}
+	}
+
+	entries, err := loadManifest(*manifest)
+	if err != nil {
+		return err
+	}
+	req.Items = candyMachineItems(entries)
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("deploying a candy machine of %v items...\n", len(req.Items))
+	deployed, err := m.DeployCandyMachine(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("candy machine: %v\ncandy guard: %v\n", deployed.CandyMachine.ToBase58(), deployed.CandyGuard.ToBase58())
+	for _, signature := range deployed.Signatures {
+		fmt.Printf("signature: %v\n", signature)
+	}
+	fmt.Println()
+	return nil
+}
+
+func runMintCandyMachine(args []string) error {
+	var g globalFlags
+	var candyMachine pubkeyFlag
+	fs := flag.NewFlagSet("mint-candy-machine", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&candyMachine, "candy-machine", "Candy Machine v3 wrapped by a Candy Guard")
+	buyerKeypair := fs.String("buyer-keypair", "", "keypair paying the guards and receiving the NFT (default: the fee payer)")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "candy-machine"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	req := nft.CandyMachineMintRequest{CandyMachine: candyMachine.key}
+	if *buyerKeypair != "" {
+		req.Buyer, err = loadKeypair(*buyerKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load buyer keypair: %w", err)
+		}
+	}
+
+	minted, err := m.MintFromCandyMachine(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\nmint: %v\ntoken account: %v\n\n", minted.Signature, minted.Mint.ToBase58(), minted.TokenAccount.ToBase58())
+
+	if *wait {
+		waitForTxConfirmation(m, minted.Signature)
+	}
+	return nil
+}
+
+func runPrintEdition(args []string) error {
+	var g globalFlags
+	var master, receiver pubkeyFlag
+	fs := flag.NewFlagSet("print-edition", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&master, "master", "mint of the master edition NFT")
+	fs.Var(&receiver, "receiver", "wallet receiving the edition")
+	holderKeypair := fs.String("master-holder-keypair", "", "keypair holding the master NFT (default: the fee payer)")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "master", "receiver"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	req := nft.PrintRequest{Master: master.key, Receiver: receiver.key}
+	if *holderKeypair != "" {
+		req.MasterHolder, err = loadKeypair(*holderKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load master holder keypair: %w", err)
+		}
+	}
+
+	printed, err := m.PrintEdition(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\nedition: %v\nmint: %v\ntoken account: %v\n\n", printed.Signature, printed.Edition, printed.Mint.ToBase58(), printed.TokenAccount.ToBase58())
+
+	if *wait {
+		waitForTxConfirmation(m, printed.Signature)
+	}
+	return nil
+}
+
+func runCreateCollection(args []string) error {
+	var g globalFlags
+	var receiver pubkeyFlag
+	fs := flag.NewFlagSet("create-collection", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&receiver, "receiver", "wallet holding the collection NFT (default: the fee payer)")
+	name := fs.String("name", "", "collection name")
+	symbol := fs.String("symbol", "", "collection symbol")
+	uri := fs.String("uri", "", "off-chain metadata URI")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "name", "uri"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+	if !receiver.set {
+		receiver.key = m.FeePayer().PublicKey
+	}
+
+	created, err := m.CreateCollection(context.Background(), nft.CollectionRequest{
+		Receiver: receiver.key,
+		Name:     *name,
+		Symbol:   *symbol,
+		URI:      *uri,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\ncollection mint: %v\ntoken account: %v\n\n", created.Signature, created.Mint.ToBase58(), created.TokenAccount.ToBase58())
+
+	if *wait {
+		waitForTxConfirmation(m, created.Signature)
+	}
+	return nil
+}
+
+func runVerifyCollection(args []string) error {
+	var g globalFlags
+	var mint, collection pubkeyFlag
+	fs := flag.NewFlagSet("verify-collection", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&mint, "mint", "mint of the collection item")
+	fs.Var(&collection, "collection", "mint of the collection NFT")
+	authorityKeypair := fs.String("authority-keypair", "", "keypair of the collection update authority (default: the fee payer)")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "mint", "collection"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	authority := m.FeePayer()
+	if *authorityKeypair != "" {
+		authority, err = loadKeypair(*authorityKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load authority keypair: %w", err)
+		}
+	}
+
+	txSig, err := m.VerifyCollectionItem(context.Background(), nft.VerifyCollectionRequest{
+		Mint:       mint.key,
+		Collection: collection.key,
+		Authority:  authority,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\n\n", txSig)
+
+	if *wait {
+		waitForTxConfirmation(m, txSig)
+	}
+	return nil
+}
+
+func runUpdate(args []string) error {
+	var g globalFlags
+	var mint, collection pubkeyFlag
+	var creators creatorsFlag
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&mint, "mint", "mint of the NFT to update")
+	name := fs.String("name", "", "new NFT name")
+	uri := fs.String("uri", "", "new off-chain metadata URI")
+	fs.Var(&collection, "collection", "new collection, left unverified")
+	noCollection := fs.Bool("no-collection", false, "remove the NFT from its collection")
+	noCreators := fs.Bool("no-creators", false, "remove every creator")
+	fs.Var(&creators, "creator", "new royalty creator as ADDRESS:SHARE[:verified], repeatable, replaces the list")
+	sellerFee := sellerFeeFlag(fs)
+	makeImmutable := fs.Bool("make-immutable", false, "lock the metadata for good after this update")
+	var newAuthority, authority pubkeyFlag
+	fs.Var(&newAuthority, "new-update-authority", "hand the update authority to this key")
+	authorityKeypair := fs.String("authority-keypair", "", "keypair of the update authority (default: the fee payer)")
+	fs.Var(&authority, "authority", "update authority public key, with -plan instead of -authority-keypair")
+	var plan planFlags
+	plan.register(fs)
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "mint"); err != nil {
+		return err
+	}
+
+	req := nft.UpdateRequest{Mint: mint.key, MakeImmutable: *makeImmutable}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			req.Name = name
+		case "uri":
+			req.URI = uri
+		case "collection":
+			req.Collection = &collection.key
+		case "creator":
+			req.Creators = (*[]token_metadata.Creator)(&creators)
+		case "seller-fee-bps":
+			var bps uint16
+			bps, err = sellerFeeBasisPoints(*sellerFee)
+			req.SellerFeeBasisPoints = &bps
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if *noCollection {
+		if req.Collection != nil {
+			return fmt.Errorf("-no-collection and -collection are exclusive")
+		}
+		req.Collection = &common.PublicKey{}
+	}
+	if *noCreators {
+		if req.Creators != nil {
+			return fmt.Errorf("-no-creators and -creator are exclusive")
+		}
+		req.Creators = &[]token_metadata.Creator{}
+	}
+	if newAuthority.set {
+		req.NewUpdateAuthority = &newAuthority.key
+	}
+
+	if plan.enabled() {
+		m, err := plan.minter(&g)
+		if err != nil {
+			return err
+		}
+		if !authority.set {
+			authority.key = m.FeePayer().PublicKey
+		}
+		blockhash, err := plan.recentBlockhash(m)
+		if err != nil {
+			return err
+		}
+		planned, err := m.PlanUpdate(context.Background(), req, authority.key, blockhash)
+		if err != nil {
+			return err
+		}
+		return plan.write(planned)
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	if *authorityKeypair != "" {
+		req.UpdateAuthority, err = loadKeypair(*authorityKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load authority keypair: %w", err)
+		}
+	}
+
+	txSig, err := m.UpdateNFT(context.Background(), req)
+	if txSig == "" {
+		return err
+	}
+	fmt.Printf("signature: %v\n\n", txSig)
+	if err != nil {
+		log.Printf("warning: %v", err)
+	}
+
+	if *wait {
+		waitForTxConfirmation(m, txSig)
+	}
+	return nil
+}
+
+func runVersions(args []string) error {
+	var g globalFlags
+	var mint pubkeyFlag
+	fs := flag.NewFlagSet("versions", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&mint, "mint", "mint of the NFT")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "mint"); err != nil {
+		return err
+	}
+
+	m := g.readOnlyMinter()
+	if m.History == nil {
+		return nft.ErrNoHistory
+	}
+	versions, err := m.History.Versions(mint.key)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Printf("no recorded updates of %v\n", mint.key.ToBase58())
+		return nil
+	}
+
+	printVersion := func(n int, snapshot nft.MetadataSnapshot) {
+		fmt.Printf("version %v: name %q, uri %v, seller fee %v bps, %v creators", n, snapshot.Name, snapshot.URI, snapshot.SellerFeeBasisPoints, len(snapshot.Creators))
+		if snapshot.Collection != "" {
+			fmt.Printf(", collection %v", snapshot.Collection)
+		}
+		if snapshot.URISHA256 != "" {
+			fmt.Printf(", uri sha256 %v", snapshot.URISHA256)
+		}
+		if snapshot.URIHashError != "" {
+			fmt.Printf(", uri not hashed: %v", snapshot.URIHashError)
+		}
+		fmt.Println()
+	}
+	printVersion(0, versions[0].Old)
+	for i, version := range versions {
+		fmt.Printf("  updated %v by %v\n", version.Time.Format(time.RFC3339), version.Signature)
+		printVersion(i+1, version.New)
+	}
+	return nil
+}
+
+func runRollback(args []string) error {
+	var g globalFlags
+	var mint pubkeyFlag
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&mint, "mint", "mint of the NFT to roll back")
+	version := fs.Int("version", -1, "version to restore, as listed by versions")
+	authorityKeypair := fs.String("authority-keypair", "", "keypair of the update authority (default: the fee payer)")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "mint", "version"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	var authority types.Account
+	if *authorityKeypair != "" {
+		authority, err = loadKeypair(*authorityKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load authority keypair: %w", err)
+		}
+	}
+
+	rolledBack, err := m.Rollback(context.Background(), mint.key, *version, authority)
+	if rolledBack == nil {
+		return err
+	}
+	fmt.Printf("signature: %v\nrestored: name %q, uri %v\n\n", rolledBack.Signature, rolledBack.Restored.Name, rolledBack.Restored.URI)
+	if rolledBack.URIChanged {
+		log.Printf("warning: the content at %v changed since version %v was recorded", rolledBack.Restored.URI, *version)
+	}
+	if err != nil {
+		log.Printf("warning: %v", err)
+	}
+
+	if *wait {
+		waitForTxConfirmation(m, rolledBack.Signature)
+	}
+	return nil
+}
+
+func runTransfer(args []string) error {
+	var g globalFlags
+	var tokenAccount, receiver pubkeyFlag
+	fs := flag.NewFlagSet("transfer", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&tokenAccount, "token", "token account holding the NFT")
+	fs.Var(&receiver, "receiver", "wallet receiving the NFT")
+	senderKeypair := fs.String("sender-keypair", "", "keypair of the current holder (default: the fee payer)")
+	var senderKey pubkeyFlag
+	fs.Var(&senderKey, "sender", "current holder public key, with -plan instead of -sender-keypair")
+	memo := fs.String("memo", "", "message attached encrypted to the receiver")
+	var plan planFlags
+	plan.register(fs)
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	waitFinalized := fs.Bool("wait-finalized", false, "wait until the transfer is read back from a finalized block")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "token", "receiver"); err != nil {
+		return err
+	}
+
+	if plan.enabled() {
+		m, err := plan.minter(&g)
+		if err != nil {
+			return err
+		}
+		if !senderKey.set {
+			senderKey.key = m.FeePayer().PublicKey
+		}
+		blockhash, err := plan.recentBlockhash(m)
+		if err != nil {
+			return err
+		}
+		planned, err := m.PlanTransfer(context.Background(), nft.TransferRequest{
+			TokenAccount: tokenAccount.key,
+			Sender:       types.Account{PublicKey: senderKey.key},
+			Receiver:     receiver.key,
+			Memo:         []byte(*memo),
+		}, blockhash)
+		if err != nil {
+			return err
+		}
+		return plan.write(planned)
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	sender := m.FeePayer()
+	if *senderKeypair != "" {
+		sender, err = loadKeypair(*senderKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load sender keypair: %w", err)
+		}
+	}
+
+	transferred, err := m.Transfer(context.Background(), nft.TransferRequest{
+		TokenAccount: tokenAccount.key,
+		Sender:       sender,
+		Receiver:     receiver.key,
+		Memo:         []byte(*memo),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\nmint: %v\ntoken account: %v\n\n", transferred.Signature, transferred.Mint.ToBase58(), transferred.TokenAccount.ToBase58())
+
+	switch {
+	case *waitFinalized:
+		waitForTxFinalized(m, transferred.Signature)
+	case *wait:
+		waitForTxConfirmation(m, transferred.Signature)
+	}
+	return nil
+}
+
+func runTransferCompressed(args []string) error {
+	var g globalFlags
+	var asset, receiver pubkeyFlag
+	fs := flag.NewFlagSet("transfer-compressed", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&asset, "asset", "asset id of the compressed NFT")
+	fs.Var(&receiver, "receiver", "wallet receiving the NFT")
+	ownerKeypair := fs.String("owner-keypair", "", "keypair of the current owner (default: the fee payer)")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "asset", "receiver"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	owner := m.FeePayer()
+	if *ownerKeypair != "" {
+		owner, err = loadKeypair(*ownerKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load owner keypair: %w", err)
+		}
+	}
+
+	sig, err := m.TransferCompressed(context.Background(), nft.CompressedTransferRequest{
+		AssetID:  asset.key,
+		Owner:    owner,
+		Receiver: receiver.key,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\n\n", sig)
+
+	if *wait {
+		waitForTxConfirmation(m, sig)
+	}
+	return nil
+}
+
+func runDecryptMemo(args []string) error {
+	var g globalFlags
+	fs := flag.NewFlagSet("decrypt-memo", flag.ExitOnError)
+	g.register(fs)
+	signature := fs.String("signature", "", "transaction whose memos are decrypted")
+	memo := fs.String("memo", "", "encrypted memo to decrypt, instead of -signature")
+	receiverKeypair := fs.String("receiver-keypair", "", "keypair of the receiver the memo was encrypted to (default: the fee payer)")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if (*signature == "") == (*memo == "") {
+		return fmt.Errorf("exactly one of -signature and -memo is required")
+	}
+
+	receiver, err := g.feePayer()
+	if *receiverKeypair != "" {
+		receiver, err = loadKeypair(*receiverKeypair)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load receiver keypair: %w", err)
+	}
+
+	memos := []string{*memo}
+	if *signature != "" {
+		memos, err = g.readOnlyMinter().GetMemos(context.Background(), *signature)
+		if err != nil {
+			return err
+		}
+	}
+
+	decrypted := 0
+	for _, memo := range memos {
+		plaintext, err := nft.DecryptMemo(receiver, memo)
+		if errors.Is(err, nft.ErrNotEncryptedMemo) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(plaintext))
+		decrypted++
+	}
+	if decrypted == 0 {
+		return nft.ErrNotEncryptedMemo
+	}
+	return nil
+}
+
+func runSwapBuild(args []string) error {
+	var g globalFlags
+	var tokenA, partyA, tokenB, partyB pubkeyFlag
+	fs := flag.NewFlagSet("swap-build", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&tokenA, "token-a", "token account holding party A's NFT")
+	fs.Var(&partyA, "party-a", "wallet of party A")
+	fs.Var(&tokenB, "token-b", "token account holding party B's NFT")
+	fs.Var(&partyB, "party-b", "wallet of party B")
+	out := fs.String("out", "-", "file the base64 swap transaction is written to, - for stdout")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "token-a", "party-a", "token-b", "party-b"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.BuildSwap(context.Background(), nft.SwapRequest{
+		TokenA: tokenA.key,
+		PartyA: partyA.key,
+		TokenB: tokenB.key,
+		PartyB: partyB.key,
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeTransaction(*out, tx); err != nil {
+		return err
+	}
+	if *out != "-" {
+		fmt.Printf("swap written to %v, valid until its blockhash expires\n", *out)
+	}
+	return nil
+}
+
+func runSwapSign(args []string) error {
+	var g globalFlags
+	fs := flag.NewFlagSet("swap-sign", flag.ExitOnError)
+	g.register(fs)
+	txFile := fs.String("tx", "", "file holding the base64 swap transaction, signed in place")
+	partyKeypair := fs.String("party-keypair", "", "keypair of the signing party")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "tx", "party-keypair"); err != nil {
+		return err
+	}
+
+	party, err := loadKeypair(*partyKeypair)
+	if err != nil {
+		return fmt.Errorf("failed to load party keypair: %w", err)
+	}
+	tx, err := readTransaction(*txFile)
+	if err != nil {
+		return err
+	}
+	if err := nft.SignSwap(&tx, party); err != nil {
+		return err
+	}
+	if err := writeTransaction(*txFile, &tx); err != nil {
+		return err
+	}
+	fmt.Printf("%v signed %v\n", party.PublicKey.ToBase58(), *txFile)
+	return nil
+}
+
+func runSwapSend(args []string) error {
+	var g globalFlags
+	fs := flag.NewFlagSet("swap-send", flag.ExitOnError)
+	g.register(fs)
+	txFile := fs.String("tx", "", "file holding the swap transaction signed by both parties")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "tx"); err != nil {
+		return err
+	}
+
+	tx, err := readTransaction(*txFile)
+	if err != nil {
+		return err
+	}
+
+	m := g.readOnlyMinter()
+	sig, err := m.SendSwap(context.Background(), &tx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\n\n", sig)
+
+	if *wait {
+		waitForTxConfirmation(m, sig)
+	}
+	return nil
+}
+
+func runBurn(args []string) error {
+	var g globalFlags
+	var tokenAccount, destination, master pubkeyFlag
+	fs := flag.NewFlagSet("burn", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&tokenAccount, "token", "token account holding the NFT")
+	ownerKeypair := fs.String("owner-keypair", "", "keypair of the current holder (default: the fee payer)")
+	fs.Var(&destination, "destination", "wallet receiving the reclaimed rent (default: the holder)")
+	fs.Var(&master, "master", "mint of the master edition, required to burn a print edition")
+	wait := fs.Bool("wait", true, "wait for confirmation")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "token"); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+
+	owner := m.FeePayer()
+	if *ownerKeypair != "" {
+		owner, err = loadKeypair(*ownerKeypair)
+		if err != nil {
+			return fmt.Errorf("failed to load owner keypair: %w", err)
+		}
+	}
+
+	burned, err := m.Burn(context.Background(), nft.BurnRequest{
+		TokenAccount: tokenAccount.key,
+		Owner:        owner,
+		Destination:  destination.key,
+		MasterMint:   master.key,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature: %v\nmint: %v\nreclaimed: %v lamports (%.9f SOL)\n\n", burned.Signature, burned.Mint.ToBase58(), burned.ReclaimedLamports, float64(burned.ReclaimedLamports)/1e9)
+
+	if *wait {
+		waitForTxConfirmation(m, burned.Signature)
+	}
+	return nil
+}
+
+func runInfo(args []string) error {
+	var g globalFlags
+	var tokenAccount, asset pubkeyFlag
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&tokenAccount, "token", "token account holding the NFT")
+	fs.Var(&asset, "asset", "mint or compressed asset ID to read through the DAS API instead")
+	offChain := fs.Bool("off-chain", true, "also fetch and show the metadata JSON the NFT's URI points at")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+
+	switch {
+	case tokenAccount.set && asset.set:
+		return fmt.Errorf("-token and -asset are mutually exclusive")
+	case asset.set:
+		return printAssetInfo(g.readOnlyMinter(), asset.key, *offChain)
+	}
+	if err := requireFlags(fs, "token"); err != nil {
+		return err
+	}
+
+	printNFTInfo(g.readOnlyMinter(), tokenAccount.key, *offChain)
+	return nil
+}
+
+func runList(args []string) error {
+	var g globalFlags
+	var owner pubkeyFlag
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&owner, "owner", "wallet to list (default: the fee payer)")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+
+	if !owner.set {
+		feePayer, err := g.feePayer()
+		if err != nil {
+			return err
+		}
+		owner.key = feePayer.PublicKey
+	}
+
+	nfts, err := g.readOnlyMinter().ListNFTs(context.Background(), owner.key)
+	if err != nil {
+		return err
+	}
+	if len(nfts) == 0 {
+		fmt.Printf("%v holds no NFTs\n", owner.key.ToBase58())
+		return nil
+	}
+	for _, owned := range nfts {
+		fmt.Printf("%v %q token %v", owned.Mint.ToBase58(), owned.Name, owned.TokenAccount.ToBase58())
+		if owned.TokenProgram == common.Token2022ProgramID {
+			fmt.Print(" (token-2022)")
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runSnapshot(args []string) error {
+	var g globalFlags
+	var collection pubkeyFlag
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&collection, "collection", "verified collection to snapshot (default: default_collection)")
+	source := fs.String("source", "auto", "how to enumerate the collection: das, rpc (getProgramAccounts) or auto")
+	out := fs.String("out", "", "file to write, CSV when it ends in .csv and JSON otherwise (default: JSON to stdout)")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if !collection.set && g.cfg.DefaultCollection != "" {
+		collection.Set(g.cfg.DefaultCollection)
+	}
+	if !collection.set {
+		return fmt.Errorf("-collection is required when default_collection is not configured")
+	}
+
+	snapshotSource, err := parseSnapshotSource(*source)
+	if err != nil {
+		return err
+	}
+
+	holders, err := g.readOnlyMinter().SnapshotHolders(context.Background(), collection.key, snapshotSource)
+	if err != nil {
+		return err
+	}
+	if err := writeSnapshot(*out, holders); err != nil {
+		return err
+	}
+	if *out != "" && *out != "-" {
+		mints := 0
+		for _, holder := range holders {
+			mints += len(holder.Mints)
+		}
+		fmt.Printf("wrote %v holders of %v NFTs to %v\n", len(holders), mints, *out)
+	}
+	return nil
+}
+
+func runHistory(args []string) error {
+	var g globalFlags
+	var mint pubkeyFlag
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&mint, "mint", "mint of the NFT")
+	asJSON := fs.Bool("json", false, "print the activity as JSON")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "mint"); err != nil {
+		return err
+	}
+
+	activity, err := g.readOnlyMinter().GetActivity(context.Background(), mint.key)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(activity)
+	}
+	if len(activity) == 0 {
+		fmt.Printf("no activity found for %v\n", mint.key.ToBase58())
+		return nil
+	}
+	for _, a := range activity {
+		when := fmt.Sprintf("slot %v", a.Slot)
+		if a.Time != nil {
+			when = a.Time.Format(time.RFC3339)
+		}
+		switch a.Kind {
+		case nft.ActivityMint:
+			fmt.Printf("%v mint to %v (%v)\n", when, a.To.ToBase58(), a.Signature)
+		case nft.ActivityBurn:
+			fmt.Printf("%v burn by %v (%v)\n", when, a.From.ToBase58(), a.Signature)
+		default:
+			fmt.Printf("%v transfer from %v to %v (%v)\n", when, a.From.ToBase58(), a.To.ToBase58(), a.Signature)
+		}
+	}
+	return nil
+}
+
+func runRarity(args []string) error {
+	var g globalFlags
+	var collection pubkeyFlag
+	fs := flag.NewFlagSet("rarity", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&collection, "collection", "verified collection to rank (default: default_collection)")
+	source := fs.String("source", "auto", "how to enumerate the collection: das, rpc (getProgramAccounts) or auto")
+	out := fs.String("out", "", "file to write the JSON report to (default: stdout)")
+	concurrency := fs.Int("concurrency", 8, "number of metadata JSON fetches in flight at once")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if !collection.set && g.cfg.DefaultCollection != "" {
+		collection.Set(g.cfg.DefaultCollection)
+	}
+	if !collection.set {
+		return fmt.Errorf("-collection is required when default_collection is not configured")
+	}
+	snapshotSource, err := parseSnapshotSource(*source)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	members, err := g.readOnlyMinter().CollectionMembers(ctx, collection.key, snapshotSource)
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("collection %v has no verified members", collection.key.ToBase58())
+	}
+	report := metadata.Rank(fetchRarityItems(ctx, members, *concurrency))
+
+	w := os.Stdout
+	if *out != "" && *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if w != os.Stdout {
+		fmt.Printf("ranked %v of %v NFTs into %v\n", report.Total, len(members), *out)
+	}
+	return nil
+}
+
+func runBalance(args []string) error {
+	var g globalFlags
+	var address pubkeyFlag
+	fs := flag.NewFlagSet("balance", flag.ExitOnError)
+	g.register(fs)
+	fs.Var(&address, "address", "account to query (default: the fee payer)")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+
+	if !address.set {
+		feePayer, err := g.feePayer()
+		if err != nil {
+			return err
+		}
+		address.key = feePayer.PublicKey
+	}
+
+	balance, err := g.client().GetBalance(context.Background(), address.key.ToBase58())
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+	fmt.Printf("%v: %v lamports (%.9f SOL)\n", address.key.ToBase58(), balance, float64(balance)/1e9)
+	return nil
+}
+
+func runSimulate(args []string) error {
+	var g globalFlags
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	g.register(fs)
+	txFile := fs.String("tx", "", "file holding a base64 serialized transaction, - for stdin")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "tx"); err != nil {
+		return err
+	}
+
+	tx, err := readTransaction(*txFile)
+	if err != nil {
+		return err
+	}
+
+	report, err := g.readOnlyMinter().Simulate(context.Background(), tx)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printSimulationReport(report)
+	return nil
+}
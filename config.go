@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+	"gopkg.in/yaml.v3"
+
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// defaultHistoryPath records metadata updates unless history_file says
+// otherwise; an empty history_file disables the history.
+const defaultHistoryPath = "solana-nft-demo-history.jsonl"
+
+// defaultConfigPath is read when present; -config or SOLANA_NFT_CONFIG point
+// elsewhere.
+const defaultConfigPath = "solana-nft-demo.yaml"
+
+// config is loaded from a YAML file, then overridden by SOLANA_NFT_* env
+// vars, then by command-line flags.
+type config struct {
+	RPCEndpoint       string `yaml:"rpc_endpoint"`
+	Commitment        string `yaml:"commitment"`
+	FeePayerKeypair   string `yaml:"fee_payer_keypair"`
+	DefaultCollection string `yaml:"default_collection"`
+	TxVersion         string `yaml:"tx_version"`
+	// DASEndpoint serves the DAS API; empty means RPCEndpoint.
+	DASEndpoint string `yaml:"das_endpoint"`
+	// WSEndpoint serves confirmation subscriptions; empty derives it from
+	// RPCEndpoint, "off" polls instead.
+	WSEndpoint  string `yaml:"ws_endpoint"`
+	HistoryFile string `yaml:"history_file"`
+	// DenyList is a file of addresses transfers and swaps may not reach, one
+	// per line with an optional reason; empty disables screening.
+	DenyList string `yaml:"deny_list"`
+	// ScreeningLog is appended a JSON line per screening decision.
+	ScreeningLog string `yaml:"screening_log"`
+	// DefaultTree is the Bubblegum tree of mint-compressed, set by
+	// create-tree.
+	DefaultTree string `yaml:"default_tree"`
+	// Storage is where upload-metadata puts files: irys, pinata, nft.storage
+	// or web3.storage.
+	Storage string `yaml:"storage"`
+	// IrysNode is the Irys node of irys storage; empty picks the devnet
+	// node on devnet and the mainnet node otherwise.
+	IrysNode string `yaml:"irys_node"`
+	// Pinata credentials of pinata storage.
+	PinataAPIKey    string `yaml:"pinata_api_key"`
+	PinataAPISecret string `yaml:"pinata_api_secret"`
+	// StorageToken is the API token of nft.storage or web3.storage.
+	StorageToken string `yaml:"storage_token"`
+}
+
+func defaultConfig() config {
+	return config{
+		RPCEndpoint:     "devnet",
+		Commitment:      string(rpc.CommitmentConfirmed),
+		FeePayerKeypair: defaultKeypairPath(),
+		TxVersion:       types.MessageVersionLegacy,
+		HistoryFile:     defaultHistoryPath,
+		Storage:         "irys",
+	}
+}
+
+// loadConfig reads path over the defaults and applies env overrides. A
+// missing file is only an error when the path was given explicitly.
+func loadConfig(path string, explicit bool) (config, error) {
+	cfg := defaultConfig()
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return config{}, fmt.Errorf("failed to parse config %v: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+	default:
+		return config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	for env, field := range map[string]*string{
+		"SOLANA_NFT_RPC_ENDPOINT":       &cfg.RPCEndpoint,
+		"SOLANA_NFT_COMMITMENT":         &cfg.Commitment,
+		"SOLANA_NFT_FEE_PAYER_KEYPAIR":  &cfg.FeePayerKeypair,
+		"SOLANA_NFT_DEFAULT_COLLECTION": &cfg.DefaultCollection,
+		"SOLANA_NFT_TX_VERSION":         &cfg.TxVersion,
+		"SOLANA_NFT_DAS_ENDPOINT":       &cfg.DASEndpoint,
+		"SOLANA_NFT_WS_ENDPOINT":        &cfg.WSEndpoint,
+		"SOLANA_NFT_HISTORY_FILE":       &cfg.HistoryFile,
+		"SOLANA_NFT_DENY_LIST":          &cfg.DenyList,
+		"SOLANA_NFT_SCREENING_LOG":      &cfg.ScreeningLog,
+		"SOLANA_NFT_DEFAULT_TREE":       &cfg.DefaultTree,
+		"SOLANA_NFT_STORAGE":            &cfg.Storage,
+		"SOLANA_NFT_IRYS_NODE":          &cfg.IrysNode,
+		"SOLANA_NFT_PINATA_API_KEY":     &cfg.PinataAPIKey,
+		"SOLANA_NFT_PINATA_API_SECRET":  &cfg.PinataAPISecret,
+		"SOLANA_NFT_STORAGE_TOKEN":      &cfg.StorageToken,
+	} {
+		if v, ok := os.LookupEnv(env); ok {
+			*field = v
+		}
+	}
+
+	cfg.FeePayerKeypair = expandHome(cfg.FeePayerKeypair)
+	cfg.HistoryFile = expandHome(cfg.HistoryFile)
+	cfg.DenyList = expandHome(cfg.DenyList)
+	cfg.ScreeningLog = expandHome(cfg.ScreeningLog)
+	return cfg, cfg.validate()
+}
+
+func (c config) validate() error {
+	switch rpc.Commitment(c.Commitment) {
+	case rpc.CommitmentProcessed, rpc.CommitmentConfirmed, rpc.CommitmentFinalized:
+	default:
+		return fmt.Errorf("invalid commitment %q, want processed, confirmed or finalized", c.Commitment)
+	}
+	switch c.TxVersion {
+	case types.MessageVersionLegacy, types.MessageVersionV0:
+	default:
+		return fmt.Errorf("invalid tx_version %q, want legacy or v0", c.TxVersion)
+	}
+	if c.DefaultCollection != "" {
+		if _, err := nft.ParsePublicKey(c.DefaultCollection); err != nil {
+			return fmt.Errorf("invalid default_collection: %w", err)
+		}
+	}
+	switch c.Storage {
+	case "irys", "pinata", "nft.storage", "web3.storage":
+	default:
+		return fmt.Errorf("invalid storage %q, want irys, pinata, nft.storage or web3.storage", c.Storage)
+	}
+	if c.DefaultTree != "" {
+		if _, err := nft.ParsePublicKey(c.DefaultTree); err != nil {
+			return fmt.Errorf("invalid default_tree: %w", err)
+		}
+	}
+	return nil
+}
+
+// setConfigValue sets key in the config file at path, creating the file if
+// needed and keeping its other settings and comments.
+func setConfigValue(path, key, value string) error {
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse config %v: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config %v is not a mapping", path)
+	}
+
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			root.Content[i+1].SetString(value)
+			found = true
+		}
+	}
+	if !found {
+		valueNode := &yaml.Node{}
+		valueNode.SetString(value)
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, valueNode)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
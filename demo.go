@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/blocto/solana-go-sdk/types"
+
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// runDemo mints an NFT to a fresh wallet and transfers it to another one,
+// printing the NFT state after each step. Fees are paid by the configured
+// fee payer.
+func runDemo(args []string) error {
+	var g globalFlags
+	fs := flag.NewFlagSet("demo", flag.ExitOnError)
+	g.register(fs)
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+
+	m, err := g.minter()
+	if err != nil {
+		return err
+	}
+	feePayer := m.FeePayer()
+	fmt.Printf("feePayer: %v\n\n", feePayer.PublicKey.ToBase58())
+
+	// user1 only signs the transfer, the fee payer covers every fee
+	user1 := types.NewAccount()
+	fmt.Printf("user1: %v\n\n", user1.PublicKey.ToBase58())
+
+	c := m.Client()
+
+	//show feePayer balance
+	balance, err := c.GetBalance(
+		context.TODO(),
+		feePayer.PublicKey.ToBase58(),
+	)
+	if err != nil {
+		log.Fatalf("failed to get balance, err: %v", err)
+	}
+	fmt.Printf("feePayer balance: %v\n\n", balance)
+
+	//show user1 balance
+	balance, err = c.GetBalance(
+		context.TODO(),
+		user1.PublicKey.ToBase58(),
+	)
+	if err != nil {
+		log.Fatalf("failed to get balance, err: %v", err)
+	}
+	fmt.Printf("user1 balance: %v\n\n", balance)
+
+	collection, err := m.CreateCollection(context.Background(), nft.CollectionRequest{Receiver: feePayer.PublicKey, Name: "game collection", URI: "ipfs://collection"})
+	if err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	fmt.Printf("collection: %v\n\n", collection.Mint.ToBase58())
+	waitForTxConfirmation(m, collection.Signature)
+
+	receiver := types.NewAccount()
+	fmt.Printf("receiver: %v\n\n", receiver.PublicKey.ToBase58())
+
+	minted, err := m.Mint(context.Background(), nft.MintRequest{Receiver: user1.PublicKey, Name: "game nft 1", URI: "ipfs://123", Collection: collection.Mint, VerifyCollection: true})
+	if err != nil {
+		return fmt.Errorf("failed to mint nft: %w", err)
+	}
+	fmt.Printf("NFT: %v\n\n", minted.Mint.ToBase58())
+	waitForTxConfirmation(m, minted.Signature)
+
+	printNFTInfo(m, minted.TokenAccount, false)
+
+	transferred, err := m.Transfer(context.Background(), nft.TransferRequest{TokenAccount: minted.TokenAccount, Sender: user1, Receiver: receiver.PublicKey})
+	if err != nil {
+		return fmt.Errorf("failed to transfer nft: %w", err)
+	}
+	waitForTxConfirmation(m, transferred.Signature)
+
+	printNFTInfo(m, transferred.TokenAccount, false)
+
+	return nil
+}
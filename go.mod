@@ -2,13 +2,18 @@ module XChenLabs/solana-nft-demo
 
 go 1.22.3
 
-require github.com/tyler-smith/go-bip39 v1.1.0
+require gopkg.in/yaml.v3 v3.0.1
+
+require golang.org/x/sys v0.0.0-20190412213103-97732733099d // indirect
 
 require (
-	filippo.io/edwards25519 v1.0.0-rc.1 // indirect
-	github.com/blocto/solana-go-sdk v1.30.0 // indirect
+	filippo.io/edwards25519 v1.0.0-rc.1
+	github.com/blocto/solana-go-sdk v1.30.0
 	github.com/davecgh/go-spew v1.1.1
-	github.com/mr-tron/base58 v1.2.0 // indirect
-	github.com/near/borsh-go v0.3.2-0.20220516180422-1ff87d108454 // indirect
-	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9 // indirect
+	github.com/gorilla/websocket v1.5.3
+	github.com/mr-tron/base58 v1.2.0
+	github.com/near/borsh-go v0.3.2-0.20220516180422-1ff87d108454
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	golang.org/x/text v0.21.0
 )
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// manifestEntry is one NFT of a batch mint manifest. Symbol is optional;
+// Collection and Creators too, falling back to the batch-wide collection and
+// creators.
+type manifestEntry struct {
+	Name       string            `json:"name"`
+	Symbol     string            `json:"symbol,omitempty"`
+	URI        string            `json:"uri"`
+	Receiver   string            `json:"receiver"`
+	Collection string            `json:"collection,omitempty"`
+	Creators   []manifestCreator `json:"creators,omitempty"`
+}
+
+type manifestCreator struct {
+	Address  string `json:"address"`
+	Share    uint8  `json:"share"`
+	Verified bool   `json:"verified,omitempty"`
+}
+
+// loadManifest reads a JSON array of entries, or a CSV file with a header
+// row naming the name, uri, receiver and optional symbol, collection,
+// creators and shares columns.
+func loadManifest(path string) ([]manifestEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []manifestEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		entries, err = readCSVManifest(f)
+	} else {
+		err = json.NewDecoder(f).Decode(&entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %v: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("manifest %v has no entries", path)
+	}
+	return entries, nil
+}
+
+// readCSVManifest reads the creators and shares columns as parallel lists
+// separated by semicolons, e.g. "A;B" and "60;40".
+func readCSVManifest(r io.Reader) ([]manifestEntry, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"name", "uri", "receiver"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %q column", name)
+		}
+	}
+	_, hasCreators := columns["creators"]
+	_, hasShares := columns["shares"]
+	if hasCreators != hasShares {
+		return nil, errors.New("the creators and shares columns go together")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []manifestEntry
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		creators, err := parseCSVCreators(field(record, "creators"), field(record, "shares"))
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+		entries = append(entries, manifestEntry{
+			Name:       field(record, "name"),
+			Symbol:     field(record, "symbol"),
+			URI:        field(record, "uri"),
+			Receiver:   field(record, "receiver"),
+			Collection: field(record, "collection"),
+			Creators:   creators,
+		})
+	}
+}
+
+func parseCSVCreators(addresses, shares string) ([]manifestCreator, error) {
+	if addresses == "" && shares == "" {
+		return nil, nil
+	}
+	addressList := strings.Split(addresses, ";")
+	shareList := strings.Split(shares, ";")
+	if len(addressList) != len(shareList) {
+		return nil, fmt.Errorf("%v creators but %v shares", len(addressList), len(shareList))
+	}
+	creators := make([]manifestCreator, len(addressList))
+	for i := range addressList {
+		share, err := strconv.ParseUint(strings.TrimSpace(shareList[i]), 10, 8)
+		if err != nil || share > 100 {
+			return nil, fmt.Errorf("invalid creator share %q, want 0 to 100", shareList[i])
+		}
+		creators[i] = manifestCreator{Address: strings.TrimSpace(addressList[i]), Share: uint8(share)}
+	}
+	return creators, nil
+}
+
+// manifestDefaults are the batch-mint settings applied to the entries.
+type manifestDefaults struct {
+	// collection and creators apply to entries that name none.
+	collection           pubkeyFlag
+	creators             []token_metadata.Creator
+	sellerFeeBasisPoints uint16
+	verify               bool
+	// truncate shortens long names and symbols instead of refusing them.
+	truncate bool
+	// feePayer is the only creator an entry may mark verified.
+	feePayer common.PublicKey
+}
+
+// mintRequests validates every entry up front so a bad row fails the batch
+// before anything is sent; the error lists every bad row. Rows are held to
+// the name, symbol, URI and creator checks of nft.CheckMintRequest. An entry
+// repeating the name, uri and receiver of an earlier one is a duplicate.
+func mintRequests(entries []manifestEntry, defaults manifestDefaults) ([]nft.MintRequest, error) {
+	reqs := make([]nft.MintRequest, len(entries))
+	var errs []error
+	seen := map[[3]string]int{}
+	for i, entry := range entries {
+		req, err := entry.mintRequest(defaults.collection, defaults.verify)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("entry %v: %w", i+1, err))
+			continue
+		}
+		key := [3]string{entry.Name, entry.URI, req.Receiver.ToBase58()}
+		if first, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("entry %v: duplicates entry %v", i+1, first))
+			continue
+		}
+		seen[key] = i + 1
+
+		if len(req.Creators) == 0 {
+			req.Creators = defaults.creators
+		}
+		req.SellerFeeBasisPoints = defaults.sellerFeeBasisPoints
+		req.LongNames = lengthPolicy(defaults.truncate, req.Name, req.Symbol)
+		if err := nft.CheckMintRequest(req, defaults.feePayer); err != nil {
+			errs = append(errs, fmt.Errorf("entry %v: %w", i+1, err))
+			continue
+		}
+		reqs[i] = req
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return reqs, nil
+}
+
+func (entry manifestEntry) mintRequest(collection pubkeyFlag, verify bool) (nft.MintRequest, error) {
+	if entry.Name == "" || entry.URI == "" {
+		return nft.MintRequest{}, errors.New("name and uri are required")
+	}
+	receiver, err := nft.ParsePublicKey(entry.Receiver)
+	if err != nil {
+		return nft.MintRequest{}, fmt.Errorf("invalid receiver: %w", err)
+	}
+
+	if entry.Collection != "" {
+		if err := collection.Set(entry.Collection); err != nil {
+			return nft.MintRequest{}, fmt.Errorf("invalid collection: %w", err)
+		}
+	}
+
+	var creators []token_metadata.Creator
+	for _, creator := range entry.Creators {
+		address, err := nft.ParsePublicKey(creator.Address)
+		if err != nil {
+			return nft.MintRequest{}, fmt.Errorf("invalid creator: %w", err)
+		}
+		creators = append(creators, token_metadata.Creator{Address: address, Share: creator.Share, Verified: creator.Verified})
+	}
+
+	return nft.MintRequest{
+		Receiver:         receiver,
+		Name:             entry.Name,
+		Symbol:           entry.Symbol,
+		URI:              entry.URI,
+		Collection:       collection.key,
+		Creators:         creators,
+		VerifyCollection: verify,
+	}, nil
+}
+
+// candyMachineItems turns the entries into the config lines of a candy
+// machine. Receivers and collections are ignored: buyers receive the items,
+// all in the collection of the candy machine.
+func candyMachineItems(entries []manifestEntry) []nft.CandyMachineItem {
+	items := make([]nft.CandyMachineItem, len(entries))
+	for i, entry := range entries {
+		items[i] = nft.CandyMachineItem{Name: entry.Name, URI: entry.URI}
+	}
+	return items
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/common"
+)
+
+const (
+	testReceiver = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	testCreator  = "8csJmhaFLM7Ha8k1VYXtYw53eCRn9BbNRfoXD5D8XMWC"
+)
+
+func writeManifest(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []manifestEntry
+		wantErr string
+	}{
+		{
+			name: "json",
+			file: "manifest.json",
+			content: `[
+				{"name": "One", "uri": "https://x/1.json", "receiver": "` + testReceiver + `"},
+				{"name": "Two", "symbol": "TW", "uri": "https://x/2.json", "receiver": "` + testReceiver + `",
+				 "creators": [{"address": "` + testCreator + `", "share": 60}, {"address": "` + testReceiver + `", "share": 40}]}
+			]`,
+			want: []manifestEntry{
+				{Name: "One", URI: "https://x/1.json", Receiver: testReceiver},
+				{Name: "Two", Symbol: "TW", URI: "https://x/2.json", Receiver: testReceiver, Creators: []manifestCreator{
+					{Address: testCreator, Share: 60},
+					{Address: testReceiver, Share: 40},
+				}},
+			},
+		},
+		{
+			name: "csv with creators and shares",
+			file: "manifest.csv",
+			content: "Name, URI, Receiver, Symbol, Creators, Shares\n" +
+				"One, https://x/1.json, " + testReceiver + ", , , \n" +
+				"Two, https://x/2.json, " + testReceiver + ", TW, " + testCreator + ";" + testReceiver + ", 60;40\n",
+			want: []manifestEntry{
+				{Name: "One", URI: "https://x/1.json", Receiver: testReceiver},
+				{Name: "Two", Symbol: "TW", URI: "https://x/2.json", Receiver: testReceiver, Creators: []manifestCreator{
+					{Address: testCreator, Share: 60},
+					{Address: testReceiver, Share: 40},
+				}},
+			},
+		},
+		{
+			name:    "csv without a required column",
+			file:    "manifest.csv",
+			content: "name,uri\nOne,https://x/1.json\n",
+			wantErr: `missing "receiver" column`,
+		},
+		{
+			name:    "csv with creators but no shares",
+			file:    "manifest.csv",
+			content: "name,uri,receiver,creators\nOne,https://x/1.json," + testReceiver + "," + testCreator + "\n",
+			wantErr: "creators and shares columns go together",
+		},
+		{
+			name:    "csv with more creators than shares",
+			file:    "manifest.csv",
+			content: "name,uri,receiver,creators,shares\nOne,https://x/1.json," + testReceiver + "," + testCreator + ";" + testReceiver + ",100\n",
+			wantErr: "line 2: 2 creators but 1 shares",
+		},
+		{
+			name:    "csv with a bad share",
+			file:    "manifest.csv",
+			content: "name,uri,receiver,creators,shares\nOne,https://x/1.json," + testReceiver + "," + testCreator + ",101\n",
+			wantErr: `line 2: invalid creator share "101"`,
+		},
+		{
+			name:    "csv with a short row",
+			file:    "manifest.csv",
+			content: "name,uri,receiver\nOne,https://x/1.json\n",
+			wantErr: "wrong number of fields",
+		},
+		{
+			name:    "malformed json",
+			file:    "manifest.json",
+			content: `[{"name": "One",`,
+			wantErr: "failed to parse manifest",
+		},
+		{
+			name:    "empty",
+			file:    "manifest.json",
+			content: `[]`,
+			wantErr: "has no entries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := loadManifest(writeManifest(t, tt.file, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %v entries, want %v", len(entries), len(tt.want))
+			}
+			for i := range entries {
+				got, want := entries[i], tt.want[i]
+				if got.Name != want.Name || got.Symbol != want.Symbol || got.URI != want.URI ||
+					got.Receiver != want.Receiver || got.Collection != want.Collection || len(got.Creators) != len(want.Creators) {
+					t.Fatalf("entry %v = %+v, want %+v", i+1, got, want)
+				}
+				for j := range got.Creators {
+					if got.Creators[j] != want.Creators[j] {
+						t.Errorf("entry %v creator %v = %+v, want %+v", i+1, j+1, got.Creators[j], want.Creators[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMintRequests(t *testing.T) {
+	var collection pubkeyFlag
+	if err := collection.Set(testCreator); err != nil {
+		t.Fatal(err)
+	}
+	defaults := manifestDefaults{collection: collection, verify: true, feePayer: common.PublicKeyFromString(testCreator)}
+
+	entries := []manifestEntry{
+		{Name: "One", URI: "https://x/1.json", Receiver: testReceiver},
+		{Name: "Two", URI: "https://x/2.json", Receiver: testReceiver, Collection: testReceiver, Creators: []manifestCreator{
+			{Address: testCreator, Share: 60, Verified: true},
+			{Address: testReceiver, Share: 40},
+		}},
+	}
+	reqs, err := mintRequests(entries, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reqs[0].Collection.ToBase58() != testCreator || len(reqs[0].Creators) != 0 || !reqs[0].VerifyCollection {
+		t.Errorf("entry 1 = %+v", reqs[0])
+	}
+	if reqs[1].Collection.ToBase58() != testReceiver || len(reqs[1].Creators) != 2 || reqs[1].Creators[0].Share != 60 {
+		t.Errorf("entry 2 = %+v", reqs[1])
+	}
+
+	// every bad row is reported, not only the first
+	bad := []manifestEntry{
+		{Name: "One", URI: "https://x/1.json", Receiver: testReceiver},
+		{Name: "", URI: "https://x/2.json", Receiver: testReceiver},
+		{Name: "Three", URI: "https://x/3.json", Receiver: "not-a-key"},
+		{Name: "One", URI: "https://x/1.json", Receiver: testReceiver},
+		{Name: "Five", URI: "https://x/5.json", Receiver: testReceiver, Collection: "nope"},
+		{Name: "Six", URI: "https://x/6.json", Receiver: testReceiver, Creators: []manifestCreator{{Address: testCreator, Share: 50}}},
+		{Name: "Seven", URI: "https://x/7.json", Receiver: testReceiver, Creators: []manifestCreator{{Address: "bad", Share: 100}}},
+		{Name: "Eight", URI: "https://x/8.json", Receiver: testReceiver, Symbol: "SYMBOLSYMBOL"},
+		{Name: "Nine", URI: "https://x/" + strings.Repeat("9", 200), Receiver: testReceiver},
+		{Name: "Ten", URI: "https://x/10.json", Receiver: testReceiver, Creators: []manifestCreator{{Address: testReceiver, Share: 100, Verified: true}}},
+		{Name: "Eleven", URI: "https://x/11.json", Receiver: testReceiver},
+	}
+	_, err = mintRequests(bad, defaults)
+	if err == nil {
+		t.Fatal("bad entries accepted")
+	}
+	for _, want := range []string{
+		"entry 2: name and uri are required",
+		"entry 3: invalid receiver",
+		"entry 4: duplicates entry 1",
+		"entry 5: invalid collection",
+		"entry 6: creator shares add up to 50",
+		"entry 7: invalid creator",
+		"entry 8: symbol",
+		"entry 9: uri is",
+		"entry 10: creator " + testReceiver + " cannot be verified",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	for _, good := range []string{"entry 1:", "entry 11:"} {
+		if strings.Contains(err.Error(), good) {
+			t.Errorf("error %q mentions the good %q", err, good)
+		}
+	}
+}
+
+func TestMintRequestsLongName(t *testing.T) {
+	path := writeManifest(t, "manifest.csv", "name,uri,receiver\n"+
+		"One,https://x/1.json,"+testReceiver+"\n"+
+		"Two,https://x/2.json,"+testReceiver+"\n"+
+		strings.Repeat("n", 33)+",https://x/3.json,"+testReceiver+"\n")
+	entries, err := loadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a long name in the last row fails the whole batch, before any mint
+	reqs, err := mintRequests(entries, manifestDefaults{feePayer: common.PublicKeyFromString(testCreator)})
+	if err == nil || !strings.Contains(err.Error(), "entry 3: name") || reqs != nil {
+		t.Fatalf("got %v requests and error %v, want entry 3 refused", len(reqs), err)
+	}
+
+	// unless long names are truncated
+	reqs, err = mintRequests(entries, manifestDefaults{truncate: true, feePayer: common.PublicKeyFromString(testCreator)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 3 {
+		t.Errorf("got %v requests, want 3", len(reqs))
+	}
+}
@@ -0,0 +1,289 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// Irys nodes accepting SOL; devnet uploads are paid in devnet SOL and expire.
+const (
+	IrysMainnetNode = "https://node1.irys.xyz"
+	IrysDevnetNode  = "https://devnet.irys.xyz"
+)
+
+// IrysDevnetRetention is roughly how long the devnet node keeps uploads.
+const IrysDevnetRetention = 60 * 24 * time.Hour
+
+// irysFundingTimeout bounds waiting for the funding transfer to confirm.
+const irysFundingTimeout = time.Minute
+
+// ed25519SignatureType is the ANS-104 signature type of Solana keys.
+const ed25519SignatureType = 2
+
+// IrysUploader uploads to Arweave through an Irys (formerly Bundlr) node as
+// ANS-104 data items signed by the payer, whose node balance is topped up
+// from its SOL when an upload costs more than it holds.
+type IrysUploader struct {
+	node       string
+	client     *client.Client
+	payer      types.Account
+	httpClient *http.Client
+
+	// TxVersion selects the message format of funding transfers, as the
+	// Minter's does.
+	TxVersion types.MessageVersion
+}
+
+// NewIrysUploader uploads through node, paying from payer; c sends the
+// funding transfers.
+func NewIrysUploader(node string, c *client.Client, payer types.Account) *IrysUploader {
+	return &IrysUploader{
+		node:       strings.TrimSuffix(node, "/"),
+		client:     c,
+		payer:      payer,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// Expires reports whether uploads are temporary, as on the devnet node,
+// which keeps them for about IrysDevnetRetention.
+func (u *IrysUploader) Expires() bool {
+	return u.node == IrysDevnetNode
+}
+
+// Upload returns the Arweave gateway URL of the upload, or the node's own
+// URL for it on the devnet node, whose uploads never reach Arweave.
+func (u *IrysUploader) Upload(ctx context.Context, name, contentType string, data []byte) (string, error) {
+
+	item := u.signDataItem(data, [][2]string{{"Content-Type", contentType}})
+	if err := u.fund(ctx, len(item)); err != nil {
+		return "", err
+	}
+
+	var uploaded struct {
+		ID string `json:"id"`
+	}
+	if err := u.do(ctx, http.MethodPost, "/tx/solana", "application/octet-stream", item, &uploaded); err != nil {
+		return "", fmt.Errorf("failed to upload %v: %w", name, err)
+	}
+	if uploaded.ID == "" {
+		return "", fmt.Errorf("irys returned no id for %v", name)
+	}
+	if u.Expires() {
+		return u.node + "/" + uploaded.ID, nil
+	}
+	return nft.ArweaveGateway + uploaded.ID, nil
+}
+
+// fund makes sure the payer's node balance covers size bytes, sending the
+// shortfall to the node's SOL address.
+func (u *IrysUploader) fund(ctx context.Context, size int) error {
+
+	var price json.Number
+	if err := u.do(ctx, http.MethodGet, fmt.Sprintf("/price/solana/%v", size), "", nil, &price); err != nil {
+		return fmt.Errorf("failed to get upload price: %w", err)
+	}
+	var balance struct {
+		Balance json.Number `json:"balance"`
+	}
+	if err := u.do(ctx, http.MethodGet, "/account/balance/solana?address="+u.payer.PublicKey.ToBase58(), "", nil, &balance); err != nil {
+		return fmt.Errorf("failed to get irys balance: %w", err)
+	}
+	shortfall, err := lamportsShortfall(price, balance.Balance)
+	if err != nil || shortfall == 0 {
+		return err
+	}
+
+	var info struct {
+		Addresses map[string]string `json:"addresses"`
+	}
+	if err := u.do(ctx, http.MethodGet, "/info", "", nil, &info); err != nil {
+		return fmt.Errorf("failed to get irys node info: %w", err)
+	}
+	address := common.PublicKeyFromString(info.Addresses["solana"])
+	if address.ToBase58() != info.Addresses["solana"] {
+		return fmt.Errorf("irys node has no valid solana address: %q", info.Addresses["solana"])
+	}
+
+	txSig, err := u.transfer(ctx, address, shortfall)
+	if err != nil {
+		return fmt.Errorf("failed to fund irys: %w", err)
+	}
+	body, err := json.Marshal(map[string]string{"tx_id": txSig})
+	if err != nil {
+		return fmt.Errorf("failed to encode irys funding: %w", err)
+	}
+	if err := u.do(ctx, http.MethodPost, "/account/balance/solana", "application/json", body, nil); err != nil {
+		return fmt.Errorf("failed to register irys funding %v: %w", txSig, err)
+	}
+	return nil
+}
+
+// lamportsShortfall is how much price exceeds balance, both in lamports.
+func lamportsShortfall(price, balance json.Number) (uint64, error) {
+	p, ok := new(big.Int).SetString(price.String(), 10)
+	if !ok {
+		return 0, fmt.Errorf("invalid irys price %q", price)
+	}
+	b, ok := new(big.Int).SetString(balance.String(), 10)
+	if !ok {
+		return 0, fmt.Errorf("invalid irys balance %q", balance)
+	}
+	if p.Cmp(b) <= 0 {
+		return 0, nil
+	}
+	return p.Sub(p, b).Uint64(), nil
+}
+
+// transfer sends lamports from the payer and waits for it to confirm, as the
+// node only credits confirmed transfers.
+func (u *IrysUploader) transfer(ctx context.Context, to common.PublicKey, lamports uint64) (string, error) {
+
+	res, err := u.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return "", fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: nft.NewMessage(u.TxVersion, types.NewMessageParam{
+			FeePayer:        u.payer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions: []types.Instruction{
+				system.Transfer(system.TransferParam{From: u.payer.PublicKey, To: to, Amount: lamports}),
+			},
+		}),
+		Signers: []types.Account{u.payer},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to new tx: %w", err)
+	}
+	txSig, err := u.client.SendTransaction(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, irysFundingTimeout)
+	defer cancel()
+	for {
+		status, err := u.client.GetSignatureStatus(ctx, txSig)
+		if err == nil && status != nil {
+			if status.Err != nil {
+				return "", fmt.Errorf("tx %v failed: %v", txSig, status.Err)
+			}
+			if status.ConfirmationStatus != nil && *status.ConfirmationStatus != rpc.CommitmentProcessed {
+				return txSig, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("tx %v not confirmed: %w", txSig, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// do calls the node and decodes its JSON response into result, if any.
+func (u *IrysUploader) do(ctx context.Context, method, path, contentType string, body []byte, result any) error {
+
+	req, err := http.NewRequestWithContext(ctx, method, u.node+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res, err := u.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("irys returned status %v: %s", res.StatusCode, data)
+	}
+	if result == nil {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(result); err != nil {
+		return fmt.Errorf("unexpected irys response %s: %w", data, err)
+	}
+	return nil
+}
+
+// signDataItem builds an ANS-104 data item of data with tags, without target
+// or anchor, signed by the payer.
+func (u *IrysUploader) signDataItem(data []byte, tags [][2]string) []byte {
+
+	owner := u.payer.PublicKey.Bytes()
+	rawTags := encodeAvroTags(tags)
+	signature := u.payer.Sign(deepHash([][]byte{
+		[]byte("dataitem"),
+		[]byte("1"),
+		[]byte(strconv.Itoa(ed25519SignatureType)),
+		owner,
+		nil, // target
+		nil, // anchor
+		rawTags,
+		data,
+	}))
+
+	item := binary.LittleEndian.AppendUint16(nil, ed25519SignatureType)
+	item = append(item, signature...)
+	item = append(item, owner...)
+	item = append(item, 0, 0) // no target, no anchor
+	item = binary.LittleEndian.AppendUint64(item, uint64(len(tags)))
+	item = binary.LittleEndian.AppendUint64(item, uint64(len(rawTags)))
+	item = append(item, rawTags...)
+	return append(item, data...)
+}
+
+// deepHash is the ANS-104 SHA-384 hash of a list of blobs.
+func deepHash(chunks [][]byte) []byte {
+	acc := sha512.Sum384([]byte("list" + strconv.Itoa(len(chunks))))
+	for _, chunk := range chunks {
+		tag := sha512.Sum384([]byte("blob" + strconv.Itoa(len(chunk))))
+		blob := sha512.Sum384(chunk)
+		chunkHash := sha512.Sum384(append(tag[:], blob[:]...))
+		acc = sha512.Sum384(append(acc[:], chunkHash[:]...))
+	}
+	return acc[:]
+}
+
+// encodeAvroTags encodes tags as the Avro array of {name, value} bytes
+// records that ANS-104 uses; no tags encode to nothing.
+func encodeAvroTags(tags [][2]string) []byte {
+	if len(tags) == 0 {
+		return nil
+	}
+	out := binary.AppendVarint(nil, int64(len(tags)))
+	for _, tag := range tags {
+		for _, field := range tag {
+			out = binary.AppendVarint(out, int64(len(field)))
+			out = append(out, field...)
+		}
+	}
+	return append(out, 0)
+}
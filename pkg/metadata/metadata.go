@@ -0,0 +1,105 @@
+// Package metadata builds the off-chain JSON of an NFT, the document its
+// on-chain URI points at, and uploads it with its image to a storage
+// provider.
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Metadata is the Metaplex token standard JSON of an NFT.
+type Metadata struct {
+	Name                 string      `json:"name"`
+	Symbol               string      `json:"symbol,omitempty"`
+	Description          string      `json:"description,omitempty"`
+	SellerFeeBasisPoints uint16      `json:"seller_fee_basis_points,omitempty"`
+	Image                string      `json:"image,omitempty"`
+	AnimationURL         string      `json:"animation_url,omitempty"`
+	ExternalURL          string      `json:"external_url,omitempty"`
+	Attributes           []Attribute `json:"attributes,omitempty"`
+	Properties           Properties  `json:"properties"`
+}
+
+// Attribute is one trait. Value is a string or a number.
+type Attribute struct {
+	TraitType string `json:"trait_type"`
+	Value     any    `json:"value"`
+}
+
+type Properties struct {
+	Files    []File    `json:"files,omitempty"`
+	Category string    `json:"category,omitempty"`
+	Creators []Creator `json:"creators,omitempty"`
+}
+
+type File struct {
+	URI  string `json:"uri"`
+	Type string `json:"type"`
+}
+
+type Creator struct {
+	Address string `json:"address"`
+	Share   uint8  `json:"share"`
+}
+
+// Uploader stores content with a storage provider and returns its URI.
+type Uploader interface {
+	Upload(ctx context.Context, name, contentType string, data []byte) (string, error)
+}
+
+// Asset is a file uploaded along with the metadata, usually its image.
+type Asset struct {
+	Name string
+	// ContentType is guessed from Name, then from Data, when empty.
+	ContentType string
+	Data        []byte
+}
+
+func (a Asset) contentType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+	if t := mime.TypeByExtension(filepath.Ext(a.Name)); t != "" {
+		return t
+	}
+	return http.DetectContentType(a.Data)
+}
+
+// Publish uploads image, points md at it and uploads md. The returned URI
+// of the JSON is what an NFT is minted with.
+func Publish(ctx context.Context, uploader Uploader, md Metadata, image Asset) (string, error) {
+	if md.Name == "" {
+		return "", errors.New("metadata has no name")
+	}
+	if len(image.Data) == 0 {
+		return "", errors.New("metadata has no image")
+	}
+
+	contentType := image.contentType()
+	imageURI, err := uploader.Upload(ctx, image.Name, contentType, image.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload image: %w", err)
+	}
+	md.Image = imageURI
+	md.Properties.Files = append([]File{{URI: imageURI, Type: contentType}}, md.Properties.Files...)
+	if kind, _, _ := strings.Cut(contentType, "/"); md.Properties.Category == "" && (kind == "image" || kind == "video" || kind == "audio") {
+		md.Properties.Category = kind
+	}
+
+	data, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	uri, err := uploader.Upload(ctx, strings.TrimSuffix(image.Name, filepath.Ext(image.Name))+".json", "application/json", data)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload metadata: %w", err)
+	}
+	return uri, nil
+}
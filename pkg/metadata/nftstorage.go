@@ -0,0 +1,75 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Upload endpoints of the free pinning services sharing the NFT.Storage
+// HTTP API.
+const (
+	NFTStorageEndpoint  = "https://api.nft.storage/upload"
+	Web3StorageEndpoint = "https://api.web3.storage/upload"
+)
+
+// NFTStorageUploader pins content to IPFS through NFT.Storage or
+// web3.storage with an API token and returns ipfs:// URIs.
+type NFTStorageUploader struct {
+	endpoint   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewNFTStorageUploader posts to endpoint, NFTStorageEndpoint or
+// Web3StorageEndpoint, authenticated by token.
+func NewNFTStorageUploader(endpoint, token string) *NFTStorageUploader {
+	return &NFTStorageUploader{endpoint: endpoint, token: token, httpClient: http.DefaultClient}
+}
+
+func (u *NFTStorageUploader) Upload(ctx context.Context, name, contentType string, data []byte) (string, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to build upload request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+u.token)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Name", name)
+
+	res, err := u.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %v: %w", name, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read upload response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("upload of %v returned status %v: %s", name, res.StatusCode, body)
+	}
+
+	// NFT.Storage wraps the CID in value, web3.storage does not
+	var uploaded struct {
+		CID   string `json:"cid"`
+		Value struct {
+			CID string `json:"cid"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(body, &uploaded); err != nil {
+		return "", fmt.Errorf("unexpected upload response: %s", body)
+	}
+	cid := uploaded.CID
+	if cid == "" {
+		cid = uploaded.Value.CID
+	}
+	if cid == "" {
+		return "", fmt.Errorf("unexpected upload response: %s", body)
+	}
+	return "ipfs://" + cid, nil
+}
@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+)
+
+const pinataPinFileURL = "https://api.pinata.cloud/pinning/pinFileToIPFS"
+
+// PinataUploader pins content to IPFS through Pinata and returns ipfs://
+// URIs.
+type PinataUploader struct {
+	apiKey     string
+	apiSecret  string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewPinataUploader authenticates with a Pinata API key and its secret.
+func NewPinataUploader(apiKey, apiSecret string) *PinataUploader {
+	return &PinataUploader{
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		endpoint:   pinataPinFileURL,
+		httpClient: http.DefaultClient,
+	}
+}
+
+func (u *PinataUploader) Upload(ctx context.Context, name, contentType string, data []byte) (string, error) {
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, name))
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to build pinata request: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("failed to build pinata request: %w", err)
+	}
+	pinataMetadata, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return "", fmt.Errorf("failed to build pinata request: %w", err)
+	}
+	if err := form.WriteField("pinataMetadata", string(pinataMetadata)); err != nil {
+		return "", fmt.Errorf("failed to build pinata request: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build pinata request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.endpoint, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to build pinata request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("pinata_api_key", u.apiKey)
+	req.Header.Set("pinata_secret_api_key", u.apiSecret)
+
+	res, err := u.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to pin %v: %w", name, err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pinata response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pinata returned status %v: %s", res.StatusCode, resBody)
+	}
+
+	var pinned struct {
+		IpfsHash string `json:"IpfsHash"`
+	}
+	if err := json.Unmarshal(resBody, &pinned); err != nil || pinned.IpfsHash == "" {
+		return "", fmt.Errorf("unexpected pinata response: %s", resBody)
+	}
+	return "ipfs://" + pinned.IpfsHash, nil
+}
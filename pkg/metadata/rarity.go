@@ -0,0 +1,144 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NoTrait is the value reported for an item lacking a trait other items of
+// the collection have, so that missing a common trait is rare too. It is
+// counted apart from a real "None" value.
+const NoTrait = "None"
+
+// traitValue is a value counted for a trait type; missing is the bucket of
+// items lacking the trait.
+type traitValue struct {
+	value   string
+	missing bool
+}
+
+// RarityItem is an NFT to rank with the attributes of its metadata JSON.
+type RarityItem struct {
+	Mint       string
+	Attributes []Attribute
+}
+
+// TraitCount is how many items of a collection have a trait value.
+type TraitCount struct {
+	TraitType string `json:"trait_type"`
+	Value     string `json:"value"`
+	Count     int    `json:"count"`
+	// Missing marks the count of items lacking the trait, whose Value is
+	// NoTrait.
+	Missing bool `json:"missing,omitempty"`
+	// Frequency is Count over the number of items.
+	Frequency float64 `json:"frequency"`
+}
+
+// RankedItem is an item with its rarity score and rank; rank 1 is the
+// rarest and tied items share a rank.
+type RankedItem struct {
+	Rank       int         `json:"rank"`
+	Mint       string      `json:"mint"`
+	Score      float64     `json:"score"`
+	Attributes []Attribute `json:"attributes"`
+}
+
+// RarityReport is the trait statistics and ranking of a collection.
+type RarityReport struct {
+	Total  int          `json:"total"`
+	Traits []TraitCount `json:"traits"`
+	Items  []RankedItem `json:"items"`
+}
+
+// Rank scores each item by statistical rarity, the sum over every trait
+// type of the collection of 1 / the frequency of the item's value, and
+// ranks them from the highest score down. Items lacking a trait type count
+// as having NoTrait. An item listing a trait type more than once counts its
+// first value only.
+func Rank(items []RarityItem) RarityReport {
+
+	// values[item][trait type]
+	values := make([]map[string]traitValue, len(items))
+	counts := map[string]map[traitValue]int{}
+	for i, item := range items {
+		values[i] = map[string]traitValue{}
+		for _, attribute := range item.Attributes {
+			if _, ok := values[i][attribute.TraitType]; ok {
+				continue
+			}
+			value := traitValue{value: fmt.Sprint(attribute.Value)}
+			values[i][attribute.TraitType] = value
+			if counts[attribute.TraitType] == nil {
+				counts[attribute.TraitType] = map[traitValue]int{}
+			}
+			counts[attribute.TraitType][value]++
+		}
+	}
+	// sorted so that scores are summed in the same order for every item
+	traitTypes := make([]string, 0, len(counts))
+	for traitType, valueCounts := range counts {
+		traitTypes = append(traitTypes, traitType)
+		present := 0
+		for _, count := range valueCounts {
+			present += count
+		}
+		if present < len(items) {
+			valueCounts[traitValue{value: NoTrait, missing: true}] = len(items) - present
+		}
+	}
+	sort.Strings(traitTypes)
+
+	total := float64(len(items))
+	report := RarityReport{Total: len(items), Items: make([]RankedItem, len(items))}
+	for traitType, valueCounts := range counts {
+		for value, count := range valueCounts {
+			report.Traits = append(report.Traits, TraitCount{
+				TraitType: traitType,
+				Value:     value.value,
+				Count:     count,
+				Missing:   value.missing,
+				Frequency: float64(count) / total,
+			})
+		}
+	}
+	sort.Slice(report.Traits, func(i, j int) bool {
+		a, b := report.Traits[i], report.Traits[j]
+		if a.TraitType != b.TraitType {
+			return a.TraitType < b.TraitType
+		}
+		if a.Count != b.Count {
+			return a.Count < b.Count
+		}
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return !a.Missing
+	})
+
+	for i, item := range items {
+		score := 0.0
+		for _, traitType := range traitTypes {
+			value, ok := values[i][traitType]
+			if !ok {
+				value = traitValue{value: NoTrait, missing: true}
+			}
+			score += total / float64(counts[traitType][value])
+		}
+		report.Items[i] = RankedItem{Mint: item.Mint, Score: score, Attributes: item.Attributes}
+	}
+	sort.Slice(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Mint < b.Mint
+	})
+	for i := range report.Items {
+		report.Items[i].Rank = i + 1
+		if i > 0 && report.Items[i].Score == report.Items[i-1].Score {
+			report.Items[i].Rank = report.Items[i-1].Rank
+		}
+	}
+	return report
+}
@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"math"
+	"testing"
+)
+
+func attributes(pairs ...string) []Attribute {
+	var attrs []Attribute
+	for i := 0; i < len(pairs); i += 2 {
+		attrs = append(attrs, Attribute{TraitType: pairs[i], Value: pairs[i+1]})
+	}
+	return attrs
+}
+
+func TestRank(t *testing.T) {
+	type ranked struct {
+		rank  int
+		mint  string
+		score float64
+	}
+	tests := []struct {
+		name       string
+		items      []RarityItem
+		want       []ranked
+		wantTraits []TraitCount
+	}{
+		{
+			name: "scores and shared ranks",
+			items: []RarityItem{
+				{Mint: "A", Attributes: attributes("Color", "red", "Hat", "cap")},
+				{Mint: "B", Attributes: attributes("Color", "red")},
+				{Mint: "C", Attributes: attributes("Color", "blue")},
+				{Mint: "D", Attributes: attributes("Color", "red")},
+			},
+			want: []ranked{
+				{1, "A", 4.0/3 + 4},
+				{1, "C", 4 + 4.0/3},
+				{3, "B", 4.0/3 + 4.0/3},
+				{3, "D", 4.0/3 + 4.0/3},
+			},
+			wantTraits: []TraitCount{
+				{TraitType: "Color", Value: "blue", Count: 1, Frequency: 0.25},
+				{TraitType: "Color", Value: "red", Count: 3, Frequency: 0.75},
+				{TraitType: "Hat", Value: "cap", Count: 1, Frequency: 0.25},
+				{TraitType: "Hat", Value: NoTrait, Count: 3, Missing: true, Frequency: 0.75},
+			},
+		},
+		{
+			name: "a real None is not a missing trait",
+			items: []RarityItem{
+				{Mint: "A", Attributes: attributes("Hat", "None")},
+				{Mint: "B", Attributes: attributes("Hat", "cap")},
+				{Mint: "C"},
+				{Mint: "D"},
+			},
+			want: []ranked{
+				{1, "A", 4},
+				{1, "B", 4},
+				{3, "C", 2},
+				{3, "D", 2},
+			},
+			wantTraits: []TraitCount{
+				{TraitType: "Hat", Value: "None", Count: 1, Frequency: 0.25},
+				{TraitType: "Hat", Value: "cap", Count: 1, Frequency: 0.25},
+				{TraitType: "Hat", Value: NoTrait, Count: 2, Missing: true, Frequency: 0.5},
+			},
+		},
+		{
+			name: "a repeated trait type counts once",
+			items: []RarityItem{
+				{Mint: "A", Attributes: attributes("Color", "red", "Color", "blue")},
+				{Mint: "B", Attributes: attributes("Color", "blue")},
+			},
+			want: []ranked{
+				{1, "A", 2},
+				{1, "B", 2},
+			},
+			wantTraits: []TraitCount{
+				{TraitType: "Color", Value: "blue", Count: 1, Frequency: 0.5},
+				{TraitType: "Color", Value: "red", Count: 1, Frequency: 0.5},
+			},
+		},
+		{
+			name: "non-string values",
+			items: []RarityItem{
+				{Mint: "A", Attributes: []Attribute{{TraitType: "Level", Value: 1.0}}},
+				{Mint: "B", Attributes: []Attribute{{TraitType: "Level", Value: 2.0}}},
+				{Mint: "C", Attributes: []Attribute{{TraitType: "Level", Value: 2.0}}},
+			},
+			want: []ranked{
+				{1, "A", 3},
+				{2, "B", 1.5},
+				{2, "C", 1.5},
+			},
+			wantTraits: []TraitCount{
+				{TraitType: "Level", Value: "1", Count: 1, Frequency: 1.0 / 3},
+				{TraitType: "Level", Value: "2", Count: 2, Frequency: 2.0 / 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Rank(tt.items)
+			if report.Total != len(tt.items) {
+				t.Errorf("total = %v, want %v", report.Total, len(tt.items))
+			}
+			if len(report.Items) != len(tt.want) {
+				t.Fatalf("got %v items, want %v", len(report.Items), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := report.Items[i]
+				if got.Rank != want.rank || got.Mint != want.mint || math.Abs(got.Score-want.score) > 1e-9 {
+					t.Errorf("item %v = rank %v %v %v, want rank %v %v %v", i, got.Rank, got.Mint, got.Score, want.rank, want.mint, want.score)
+				}
+			}
+			if len(report.Traits) != len(tt.wantTraits) {
+				t.Fatalf("traits = %+v, want %+v", report.Traits, tt.wantTraits)
+			}
+			for i, want := range tt.wantTraits {
+				got := report.Traits[i]
+				if got.TraitType != want.TraitType || got.Value != want.Value || got.Count != want.Count ||
+					got.Missing != want.Missing || math.Abs(got.Frequency-want.Frequency) > 1e-9 {
+					t.Errorf("trait %v = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
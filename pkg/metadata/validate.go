@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// fetchTimeout bounds fetching a metadata document.
+const fetchTimeout = 10 * time.Second
+
+// Fetch downloads and decodes the metadata JSON at uri.
+func Fetch(ctx context.Context, uri string) (*Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, nft.GatewayURL(uri), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %v: %w", uri, err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %v: %w", uri, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %v: status %v", uri, res.StatusCode)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v: %w", uri, err)
+	}
+	var md Metadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		return nil, fmt.Errorf("%v is not metadata JSON: %w", uri, err)
+	}
+	return &md, nil
+}
+
+// Check fetches the metadata at uri, the URI an NFT is about to be minted
+// with, and validates it.
+func Check(ctx context.Context, uri string, names nft.LengthPolicy) error {
+	if len(uri) > nft.MaxURILength {
+		return fmt.Errorf("uri is %v bytes, at most %v are allowed on-chain", len(uri), nft.MaxURILength)
+	}
+	md, err := Fetch(ctx, uri)
+	if err != nil {
+		return err
+	}
+	if err := md.Validate(names); err != nil {
+		return fmt.Errorf("invalid metadata at %v:\n%w", uri, err)
+	}
+	return nil
+}
+
+// Validate checks md against the Metaplex token standard and returns every
+// problem found, one per line. Names and symbols over the on-chain limits are
+// only accepted when names says they get truncated.
+func (md Metadata) Validate(names nft.LengthPolicy) error {
+	var problems []error
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	switch {
+	case md.Name == "":
+		problem("name is required")
+	case len(md.Name) > nft.MaxNameLength && names != nft.TruncateLongNames:
+		problem("name is %v bytes, at most %v fit on-chain", len(md.Name), nft.MaxNameLength)
+	}
+	if len(md.Symbol) > nft.MaxSymbolLength && names != nft.TruncateLongNames {
+		problem("symbol is %v bytes, at most %v fit on-chain", len(md.Symbol), nft.MaxSymbolLength)
+	}
+	if md.SellerFeeBasisPoints > 10000 {
+		problem("seller_fee_basis_points %v is over 10000", md.SellerFeeBasisPoints)
+	}
+	if md.Image == "" {
+		problem("image is required")
+	}
+
+	for i, attribute := range md.Attributes {
+		if attribute.TraitType == "" {
+			problem("attributes[%v].trait_type is required", i)
+		}
+		switch attribute.Value.(type) {
+		case string, float64, json.Number:
+		default:
+			problem("attributes[%v].value must be a string or a number, not %T", i, attribute.Value)
+		}
+	}
+
+	for i, file := range md.Properties.Files {
+		if file.URI == "" || file.Type == "" {
+			problem("properties.files[%v] needs a uri and a type", i)
+		}
+	}
+	if len(md.Properties.Creators) > 0 {
+		total := 0
+		for _, creator := range md.Properties.Creators {
+			total += int(creator.Share)
+		}
+		if total != 100 {
+			problem("properties.creators shares add up to %v, want 100", total)
+		}
+	}
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,188 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/rpc"
+)
+
+// signaturesPageLimit is the most signatures one getSignaturesForAddress
+// call returns.
+const signaturesPageLimit = 1000
+
+type ActivityKind string
+
+const (
+	ActivityMint     ActivityKind = "mint"
+	ActivityTransfer ActivityKind = "transfer"
+	ActivityBurn     ActivityKind = "burn"
+)
+
+// Activity is a transaction that moved an NFT.
+type Activity struct {
+	Kind      ActivityKind `json:"kind"`
+	Signature string       `json:"signature"`
+	Slot      uint64       `json:"slot"`
+	// Time is nil when the node does not know the block's time.
+	Time *time.Time `json:"time,omitempty"`
+	// From and To are the wallets the token left and reached; From is nil
+	// for a mint and To for a burn. They are token accounts when the node
+	// does not report token account owners.
+	From *common.PublicKey `json:"from,omitempty"`
+	To   *common.PublicKey `json:"to,omitempty"`
+}
+
+// GetActivity returns the mints, transfers and burns of mint, oldest first.
+// It walks the signatures of the mint and of every token account that held
+// it, as a plain transfer does not reference the mint, and reads the moves
+// off each transaction's token balances. Failed transactions are skipped.
+func (m *Minter) GetActivity(ctx context.Context, mint common.PublicKey) ([]Activity, error) {
+
+	// processed is not served by getSignaturesForAddress
+	commitment := m.Commitment
+	if commitment == rpc.CommitmentProcessed {
+		commitment = rpc.CommitmentConfirmed
+	}
+
+	scanned := map[common.PublicKey]bool{}
+	pending := []common.PublicKey{mint}
+	txs := map[string]*client.Transaction{}
+	for len(pending) > 0 {
+		address := pending[0]
+		pending = pending[1:]
+		if scanned[address] {
+			continue
+		}
+		scanned[address] = true
+
+		signatures, err := m.allSignatures(ctx, address, commitment)
+		if err != nil {
+			return nil, err
+		}
+		for _, signature := range signatures {
+			if signature.Err != nil || txs[signature.Signature] != nil {
+				continue
+			}
+			tx, err := m.client.GetTransactionWithConfig(ctx, signature.Signature, client.GetTransactionConfig{Commitment: commitment})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get transaction %v: %w", signature.Signature, err)
+			}
+			if tx == nil || tx.Meta == nil {
+				continue
+			}
+			txs[signature.Signature] = tx
+
+			// token accounts of the mint show up in its token balances
+			for _, balances := range [][]rpc.TransactionMetaTokenBalance{tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances} {
+				for _, balance := range balances {
+					if balance.Mint == mint.ToBase58() && int(balance.AccountIndex) < len(tx.AccountKeys) {
+						pending = append(pending, tx.AccountKeys[balance.AccountIndex])
+					}
+				}
+			}
+		}
+	}
+
+	var activity []Activity
+	for signature, tx := range txs {
+		if a, ok := activityOf(signature, tx, mint); ok {
+			activity = append(activity, a)
+		}
+	}
+	sort.Slice(activity, func(i, j int) bool {
+		if activity[i].Slot != activity[j].Slot {
+			return activity[i].Slot < activity[j].Slot
+		}
+		return activity[i].Signature < activity[j].Signature
+	})
+	return activity, nil
+}
+
+// allSignatures pages through the signatures of address, newest first.
+func (m *Minter) allSignatures(ctx context.Context, address common.PublicKey, commitment rpc.Commitment) ([]rpc.SignatureWithStatus, error) {
+	var all []rpc.SignatureWithStatus
+	before := ""
+	for {
+		page, err := m.client.GetSignaturesForAddressWithConfig(ctx, address.ToBase58(), client.GetSignaturesForAddressConfig{
+			Limit:      signaturesPageLimit,
+			Before:     before,
+			Commitment: commitment,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signatures of %v: %w", address.ToBase58(), err)
+		}
+		all = append(all, page...)
+		if len(page) < signaturesPageLimit {
+			return all, nil
+		}
+		before = page[len(page)-1].Signature
+	}
+}
+
+// activityOf reads how tx moved mint from the change of its token balances:
+// a supply increase is a mint, a decrease a burn and a move between holders
+// a transfer. Transactions leaving the balances alone are not activity.
+func activityOf(signature string, tx *client.Transaction, mint common.PublicKey) (Activity, bool) {
+
+	// pre and post amounts of each holder
+	type change struct{ pre, post uint64 }
+	changes := map[common.PublicKey]*change{}
+	holder := func(balance rpc.TransactionMetaTokenBalance) *change {
+		var key common.PublicKey
+		if balance.Owner != "" {
+			key = common.PublicKeyFromString(balance.Owner)
+		} else if int(balance.AccountIndex) < len(tx.AccountKeys) {
+			key = tx.AccountKeys[balance.AccountIndex]
+		}
+		if changes[key] == nil {
+			changes[key] = &change{}
+		}
+		return changes[key]
+	}
+	for _, balance := range tx.Meta.PreTokenBalances {
+		if balance.Mint == mint.ToBase58() {
+			amount, _ := strconv.ParseUint(balance.UITokenAmount.Amount, 10, 64)
+			holder(balance).pre += amount
+		}
+	}
+	for _, balance := range tx.Meta.PostTokenBalances {
+		if balance.Mint == mint.ToBase58() {
+			amount, _ := strconv.ParseUint(balance.UITokenAmount.Amount, 10, 64)
+			holder(balance).post += amount
+		}
+	}
+
+	a := Activity{Signature: signature, Slot: tx.Slot}
+	if tx.BlockTime != nil {
+		blockTime := time.Unix(*tx.BlockTime, 0).UTC()
+		a.Time = &blockTime
+	}
+	var pre, post uint64
+	for key, c := range changes {
+		pre += c.pre
+		post += c.post
+		switch {
+		case c.post > c.pre:
+			a.To = &key
+		case c.post < c.pre:
+			a.From = &key
+		}
+	}
+	switch {
+	case post > pre:
+		a.Kind, a.From = ActivityMint, nil
+	case post < pre:
+		a.Kind, a.To = ActivityBurn, nil
+	case a.From != nil && a.To != nil:
+		a.Kind = ActivityTransfer
+	default:
+		return Activity{}, false
+	}
+	return a, true
+}
@@ -0,0 +1,17 @@
+package nft
+
+import (
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+)
+
+// ParsePublicKey is common.PublicKeyFromString with validation; the SDK
+// helper silently turns bad input into a zero key.
+func ParsePublicKey(s string) (common.PublicKey, error) {
+	key := common.PublicKeyFromString(s)
+	if key == (common.PublicKey{}) || key.ToBase58() != s {
+		return common.PublicKey{}, fmt.Errorf("invalid address %q", s)
+	}
+	return key, nil
+}
@@ -0,0 +1,69 @@
+package nft
+
+import (
+	"context"
+	"sync"
+)
+
+type BatchOptions struct {
+	// Concurrency is the number of mints in flight at once, at least one.
+	Concurrency int
+	// WaitForConfirmation counts an item as done only once its transaction
+	// is confirmed, as Confirm says.
+	WaitForConfirmation bool
+	Confirm             ConfirmOptions
+	// Report, when set, is called as each item finishes. Calls never overlap.
+	Report func(BatchItem)
+}
+
+// BatchItem is the outcome of one request of a batch.
+type BatchItem struct {
+	Index   int
+	Request MintRequest
+	Result  *MintResult
+	Err     error
+}
+
+// MintBatch mints every request, one transaction per NFT, and returns the
+// outcomes in request order. A failed item does not stop the others.
+func (m *Minter) MintBatch(ctx context.Context, reqs []MintRequest, opts BatchOptions) []BatchItem {
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	items := make([]BatchItem, len(reqs))
+	indexes := make(chan int)
+	var reportMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item := BatchItem{Index: i, Request: reqs[i]}
+				item.Result, item.Err = m.Mint(ctx, reqs[i])
+				if item.Err == nil && opts.WaitForConfirmation {
+					item.Err = m.WaitForConfirmation(ctx, item.Result.Signature, opts.Confirm)
+				}
+				items[i] = item
+
+				if opts.Report != nil {
+					reportMu.Lock()
+					opts.Report(item)
+					reportMu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range reqs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return items
+}
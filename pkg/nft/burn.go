@@ -0,0 +1,392 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/compute_budget"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/near/borsh-go"
+)
+
+// ErrMasterMintRequired is returned when burning a print edition without
+// BurnRequest.MasterMint; the edition account only records the master's
+// edition address, from which the mint cannot be derived.
+var ErrMasterMintRequired = errors.New("burning a print edition needs the mint of its master edition")
+
+type BurnRequest struct {
+	TokenAccount common.PublicKey
+	Owner        types.Account
+	// Destination receives the reclaimed rent; the zero key means the owner.
+	Destination common.PublicKey
+	// MasterMint is the mint of the master edition when burning a print
+	// edition, and is ignored otherwise.
+	MasterMint common.PublicKey
+}
+
+type BurnResult struct {
+	Signature string
+	Mint      common.PublicKey
+	// ReclaimedLamports is the rent freed from the metadata, edition, token
+	// record and token account. The mint account itself cannot be closed.
+	ReclaimedLamports uint64
+}
+
+// editionV1 is the token metadata account of a print edition.
+type editionV1 struct {
+	Key token_metadata.Key
+	// Parent is the master edition account the print was made from.
+	Parent  common.PublicKey
+	Edition uint64
+}
+
+// burnTarget is what Burn needs to know about the NFT being burned.
+type burnTarget struct {
+	mint          common.PublicKey
+	metadata      common.PublicKey
+	edition       common.PublicKey
+	programmable  bool
+	tokenRecord   common.PublicKey
+	collectionKey *common.PublicKey
+	// print is set for print editions
+	print *printEditionAccounts
+}
+
+// printEditionAccounts are the master accounts burning a print edition
+// updates.
+type printEditionAccounts struct {
+	masterEdition      common.PublicKey
+	masterMint         common.PublicKey
+	masterTokenAccount common.PublicKey
+	editionMarker      common.PublicKey
+}
+
+// Burn burns the NFT and forwards the freed rent to the destination. The
+// instruction follows the NFT: BurnNft for a master edition, BurnEditionNft
+// for a print edition, the token metadata Burn for a pNFT and a token burn
+// and close for a Token-2022 NFT, whose mint keeps its metadata.
+func (m *Minter) Burn(ctx context.Context, req BurnRequest) (*BurnResult, error) {
+
+	feePayer := m.feePayer
+
+	//token account info
+	tokenInfo, err := m.client.GetAccountInfoWithConfig(ctx, req.TokenAccount.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+	tokenProgram := tokenInfo.Owner
+	tokenAccountData := tokenInfo.Data
+	switch tokenProgram {
+	case common.TokenProgramID:
+	case common.Token2022ProgramID:
+		tokenAccountData, _, err = splitToken2022Data(tokenAccountData, token.TokenAccountSize, token2022AccountTypeAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split token-2022 account data: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%v is not a token account", req.TokenAccount.ToBase58())
+	}
+	tokenAccount, err := token.TokenAccountFromData(tokenAccountData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data to a token account: %w", err)
+	}
+	if tokenAccount.Owner != req.Owner.PublicKey {
+		return nil, fmt.Errorf("%v is not the owner of token account %v", req.Owner.PublicKey.ToBase58(), req.TokenAccount.ToBase58())
+	}
+	mintPubkey := tokenAccount.Mint
+
+	destination := req.Destination
+	if destination == (common.PublicKey{}) {
+		destination = req.Owner.PublicKey
+	}
+
+	var instructions []types.Instruction
+	var closed []common.PublicKey
+	if tokenProgram == common.Token2022ProgramID {
+		instructions = []types.Instruction{
+			onTokenProgram(token.BurnChecked(token.BurnCheckedParam{
+				Account:  req.TokenAccount,
+				Auth:     req.Owner.PublicKey,
+				Signers:  []common.PublicKey{},
+				Mint:     mintPubkey,
+				Amount:   1,
+				Decimals: 0,
+			}), tokenProgram),
+			onTokenProgram(token.CloseAccount(token.CloseAccountParam{
+				Account: req.TokenAccount,
+				Auth:    req.Owner.PublicKey,
+				Signers: []common.PublicKey{},
+				To:      destination,
+			}), tokenProgram),
+		}
+		closed = []common.PublicKey{req.TokenAccount}
+	} else {
+		target, err := m.findBurnTarget(ctx, mintPubkey, req.TokenAccount, req.MasterMint)
+		if err != nil {
+			return nil, err
+		}
+		instruction, err := burnInstruction(target, req.Owner.PublicKey, req.TokenAccount)
+		if err != nil {
+			return nil, err
+		}
+		instructions = []types.Instruction{instruction}
+		if target.programmable {
+			instructions = append([]types.Instruction{
+				compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+					Units: programmableComputeUnits,
+				}),
+			}, instructions...)
+		}
+		closed = []common.PublicKey{target.metadata, target.edition, req.TokenAccount}
+		if target.programmable {
+			closed = append(closed, target.tokenRecord)
+		}
+	}
+
+	addresses := make([]string, 0, len(closed))
+	for _, account := range closed {
+		addresses = append(addresses, account.ToBase58())
+	}
+	accounts, err := m.client.GetMultipleAccountsWithConfig(ctx, addresses, client.GetMultipleAccountsConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts to close: %w", err)
+	}
+	var reclaimed uint64
+	for _, account := range accounts {
+		reclaimed += account.Lamports
+	}
+
+	// the token metadata program refunds the owner
+	if tokenProgram == common.TokenProgramID && destination != req.Owner.PublicKey {
+		instructions = append(instructions, system.Transfer(system.TransferParam{
+			From:   req.Owner.PublicKey,
+			To:     destination,
+			Amount: reclaimed,
+		}))
+	}
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions:    instructions,
+		}),
+		Signers: []types.Account{feePayer, req.Owner},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &BurnResult{Signature: txSig, Mint: mintPubkey, ReclaimedLamports: reclaimed}, nil
+}
+
+// findBurnTarget reads the token standard and edition of a classic NFT, and for
+// a print edition finds the master accounts, checking masterMint against the
+// edition's parent.
+func (m *Minter) findBurnTarget(ctx context.Context, mint, tokenAccount, masterMint common.PublicKey) (*burnTarget, error) {
+
+	metadataAccount, err := token_metadata.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	editionAccount, err := token_metadata.GetMasterEdition(mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid edition: %w", err)
+	}
+
+	metadata, err := m.getMetadata(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+	target := &burnTarget{mint: mint, metadata: metadataAccount, edition: editionAccount}
+	target.programmable, _ = isProgrammable(metadata)
+	if target.programmable {
+		target.tokenRecord, err = tokenRecordAddress(mint, tokenAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find a valid token record: %w", err)
+		}
+	}
+	// verified members must name their collection so a sized collection can
+	// count them out
+	if metadata.Collection != nil && metadata.Collection.Verified {
+		collectionMetadata, err := token_metadata.GetTokenMetaPubkey(metadata.Collection.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find a valid collection metadata: %w", err)
+		}
+		target.collectionKey = &collectionMetadata
+	}
+
+	editionInfo, err := m.client.GetAccountInfoWithConfig(ctx, editionAccount.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edition info: %w", err)
+	}
+	if len(editionInfo.Data) == 0 {
+		return nil, fmt.Errorf("%v has no edition account", mint.ToBase58())
+	}
+	switch token_metadata.Key(editionInfo.Data[0]) {
+	case token_metadata.KeyMasterEditionV1, token_metadata.KeyMasterEditionV2:
+		return target, nil
+	case token_metadata.KeyEditionV1:
+	default:
+		return nil, fmt.Errorf("%v has no edition account", mint.ToBase58())
+	}
+
+	var edition editionV1
+	if err := borsh.Deserialize(&edition, editionInfo.Data); err != nil {
+		return nil, fmt.Errorf("failed to parse edition: %w", err)
+	}
+	if masterMint == (common.PublicKey{}) {
+		return nil, fmt.Errorf("%w: %v is edition %v of master edition %v", ErrMasterMintRequired, mint.ToBase58(), edition.Edition, edition.Parent.ToBase58())
+	}
+	masterEdition, err := token_metadata.GetMasterEdition(masterMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid master edition: %w", err)
+	}
+	if masterEdition != edition.Parent {
+		return nil, fmt.Errorf("%v was not printed from %v", mint.ToBase58(), masterMint.ToBase58())
+	}
+	editionMarker, err := token_metadata.GetEditionMark(masterMint, edition.Edition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid edition mark: %w", err)
+	}
+
+	// the program only checks the master token account's mint, so whoever
+	// holds the master will do
+	masterHolder, held, err := m.mintHolder(ctx, masterMint)
+	if err != nil {
+		return nil, err
+	}
+	if !held {
+		return nil, fmt.Errorf("master edition %v is not held by any wallet", masterMint.ToBase58())
+	}
+	masterTokenAccount, _, err := common.FindAssociatedTokenAddress(masterHolder, masterMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the master holder's ATA: %w", err)
+	}
+
+	target.print = &printEditionAccounts{
+		masterEdition:      masterEdition,
+		masterMint:         masterMint,
+		masterTokenAccount: masterTokenAccount,
+		editionMarker:      editionMarker,
+	}
+	return target, nil
+}
+
+// burnInstruction builds the token metadata instruction burning target.
+func burnInstruction(target *burnTarget, owner, tokenAccount common.PublicKey) (types.Instruction, error) {
+
+	if target.programmable {
+		return programmableBurnInstruction(target, owner, tokenAccount)
+	}
+
+	var accountMetas []types.AccountMeta
+	var instruction token_metadata.Instruction
+	if printed := target.print; printed != nil {
+		instruction = token_metadata.InstructionBurnEditionNft
+		accountMetas = []types.AccountMeta{
+			{PubKey: target.metadata, IsSigner: false, IsWritable: true},
+			{PubKey: owner, IsSigner: true, IsWritable: true},
+			{PubKey: target.mint, IsSigner: false, IsWritable: true},
+			{PubKey: printed.masterMint, IsSigner: false, IsWritable: false},
+			{PubKey: tokenAccount, IsSigner: false, IsWritable: true},
+			{PubKey: printed.masterTokenAccount, IsSigner: false, IsWritable: false},
+			{PubKey: printed.masterEdition, IsSigner: false, IsWritable: true},
+			{PubKey: target.edition, IsSigner: false, IsWritable: true},
+			{PubKey: printed.editionMarker, IsSigner: false, IsWritable: true},
+			{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+		}
+	} else {
+		instruction = token_metadata.InstructionBurnNft
+		accountMetas = []types.AccountMeta{
+			{PubKey: target.metadata, IsSigner: false, IsWritable: true},
+			{PubKey: owner, IsSigner: true, IsWritable: true},
+			{PubKey: target.mint, IsSigner: false, IsWritable: true},
+			{PubKey: tokenAccount, IsSigner: false, IsWritable: true},
+			{PubKey: target.edition, IsSigner: false, IsWritable: true},
+			{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+		}
+		if target.collectionKey != nil {
+			accountMetas = append(accountMetas, types.AccountMeta{PubKey: *target.collectionKey, IsSigner: false, IsWritable: true})
+		}
+	}
+
+	data, err := borsh.Serialize(struct {
+		Instruction token_metadata.Instruction
+	}{
+		Instruction: instruction,
+	})
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to serialize instruction: %w", err)
+	}
+
+	return types.Instruction{
+		ProgramID: common.MetaplexTokenMetaProgramID,
+		Accounts:  accountMetas,
+		Data:      data,
+	}, nil
+}
+
+// programmableBurnInstruction builds the token metadata Burn instruction,
+// which also closes the pNFT's token record.
+func programmableBurnInstruction(target *burnTarget, owner, tokenAccount common.PublicKey) (types.Instruction, error) {
+
+	collectionMetadata := omittedAccount
+	if target.collectionKey != nil {
+		collectionMetadata = *target.collectionKey
+	}
+	masterEdition, masterMint, masterTokenAccount, editionMarker := omittedAccount, omittedAccount, omittedAccount, omittedAccount
+	if printed := target.print; printed != nil {
+		masterEdition, masterMint, masterTokenAccount, editionMarker = printed.masterEdition, printed.masterMint, printed.masterTokenAccount, printed.editionMarker
+	}
+
+	data, err := borsh.Serialize(struct {
+		Instruction token_metadata.Instruction
+		Version     uint8
+		Amount      uint64
+	}{
+		Instruction: token_metadata.InstructionBurn,
+		Version:     0, // V1
+		Amount:      1,
+	})
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to serialize burn instruction: %w", err)
+	}
+
+	return types.Instruction{
+		ProgramID: common.MetaplexTokenMetaProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: owner, IsSigner: true, IsWritable: true}, // authority
+			{PubKey: collectionMetadata, IsSigner: false, IsWritable: collectionMetadata != omittedAccount},
+			{PubKey: target.metadata, IsSigner: false, IsWritable: true},
+			{PubKey: target.edition, IsSigner: false, IsWritable: true},
+			{PubKey: target.mint, IsSigner: false, IsWritable: true},
+			{PubKey: tokenAccount, IsSigner: false, IsWritable: true},
+			{PubKey: masterEdition, IsSigner: false, IsWritable: masterEdition != omittedAccount},
+			{PubKey: masterMint, IsSigner: false, IsWritable: false},
+			{PubKey: masterTokenAccount, IsSigner: false, IsWritable: false},
+			{PubKey: editionMarker, IsSigner: false, IsWritable: editionMarker != omittedAccount},
+			{PubKey: target.tokenRecord, IsSigner: false, IsWritable: true},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+			{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+		},
+		Data: data,
+	}, nil
+}
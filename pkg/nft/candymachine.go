@@ -0,0 +1,930 @@
+package nft
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/compute_budget"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/near/borsh-go"
+)
+
+var (
+	CandyMachineProgramID = common.PublicKeyFromString("CndyV3LdqHUfDLmE5naZjVN8rBZz4tqhdefbAnjHG3JR")
+	CandyGuardProgramID   = common.PublicKeyFromString("Guard1JwRhJkVH6XZhzoYxeBVQe872VH6QggF4BWmS9g")
+)
+
+var (
+	ErrCandyMachineEmpty = errors.New("every item of the candy machine has been minted")
+	// ErrUnsupportedGuard is returned for a candy guard using a guard whose
+	// accounts or mint arguments MintFromCandyMachine does not build.
+	ErrUnsupportedGuard = errors.New("unsupported candy guard")
+	// ErrGuardFailed is returned when a guard would reject the mint, before
+	// a bot tax could be charged for it.
+	ErrGuardFailed = errors.New("candy guard would reject the mint")
+	// ErrUnsupportedRuleSet is returned for a candy machine of programmable
+	// NFTs bound to an authorization rule set, whose accounts
+	// MintFromCandyMachine does not pass.
+	ErrUnsupportedRuleSet = errors.New("candy machine rule sets are not supported")
+)
+
+// candyMachineComputeUnits covers the Candy Guard and Candy Machine Core
+// checks plus the token metadata Create and Mint they run.
+const candyMachineComputeUnits = 800_000
+
+// Offsets of the Candy Machine Core account, after its discriminator: the
+// version, token standard and features bytes, then the authority, mint
+// authority and collection mint, items redeemed and the data's items
+// available.
+const (
+	candyMachineVersionOffset        = 8
+	candyMachineTokenStandardOffset  = 9
+	candyMachineMintAuthorityOffset  = 48
+	candyMachineCollectionMintOffset = 80
+	candyMachineItemsRedeemedOffset  = 112
+	candyMachineItemsAvailableOffset = 120
+)
+
+// candyMachineHiddenSection is the size of a Candy Machine Core account
+// up to its config lines, with room for the largest data.
+const candyMachineHiddenSection = 850
+
+// Config lines without config line settings are stored at the token
+// metadata name and URI limits.
+const (
+	candyMachineNameLength   = 32
+	candyMachineSymbolLength = 10
+	candyMachineURILength    = 200
+)
+
+// candyMachineConfigLineBytes caps the name and URI bytes sent per
+// add_config_lines transaction, leaving room for its signature and accounts
+// within the 1232 byte transaction limit.
+const candyMachineConfigLineBytes = 800
+
+// candyGuardDataOffset is where the guard sets of a Candy Guard account
+// start, after its discriminator, base, bump and authority.
+const candyGuardDataOffset = 8 + 32 + 1 + 32
+
+// candyGuardNames are the guards in the order of the guard set's feature
+// bits and data.
+var candyGuardNames = []string{
+	"botTax", "solPayment", "tokenPayment", "startDate", "thirdPartySigner",
+	"tokenGate", "gatekeeper", "endDate", "allowList", "mintLimit",
+	"nftPayment", "redeemedAmount", "addressGate", "nftGate", "nftBurn",
+	"tokenBurn", "freezeSolPayment", "freezeTokenPayment", "programGate",
+	"allocation", "token2022Payment",
+}
+
+// candyGuardSizes are the data sizes of the guards MintFromCandyMachine
+// supports, by feature bit.
+var candyGuardSizes = map[int]int{
+	0:  8 + 1,  // botTax: lamports, lastInstruction
+	1:  8 + 32, // solPayment: lamports, destination
+	3:  8,      // startDate
+	7:  8,      // endDate
+	9:  1 + 2,  // mintLimit: id, limit
+	11: 8,      // redeemedAmount
+	12: 32,     // addressGate
+}
+
+// CandyMachineItem is one config line of a candy machine: the name and
+// metadata URI of the NFT minted for it.
+type CandyMachineItem struct {
+	Name string
+	URI  string
+}
+
+type CandyMachineDeployRequest struct {
+	// Collection is the collection NFT the items are verified in; the fee
+	// payer must be its update authority.
+	Collection common.PublicKey
+	Items      []CandyMachineItem
+	Symbol     string
+	// Creators share the royalties of the items. The candy machine adds
+	// itself as their first, verified creator, so at most MaxCreators-1 may
+	// be given.
+	Creators             []token_metadata.Creator
+	SellerFeeBasisPoints uint16
+	Mutable              bool
+
+	// Price is charged in lamports by the solPayment guard and paid to
+	// Treasury, the fee payer by default; zero makes the mint free.
+	Price    uint64
+	Treasury common.PublicKey
+	// BotTax is charged in lamports for a mint the guards reject, instead of
+	// failing it.
+	BotTax uint64
+	// StartDate and EndDate bound the mint when set.
+	StartDate time.Time
+	EndDate   time.Time
+	// MintLimit caps the mints per wallet when set.
+	MintLimit uint16
+}
+
+type CandyMachineDeployResult struct {
+	CandyMachine common.PublicKey
+	CandyGuard   common.PublicKey
+	// Signatures are the confirmed transactions of the deployment, in order.
+	Signatures []string
+}
+
+// DeployCandyMachine creates a Candy Machine v3 of req.Items with Candy
+// Machine Core initialize_v2, loads the items with add_config_lines, then
+// creates a Candy Guard of the requested guards and wraps the candy
+// machine with it, so buyers mint through MintFromCandyMachine. The fee
+// payer is the authority of both. Each step waits for the previous one to
+// be confirmed.
+func (m *Minter) DeployCandyMachine(ctx context.Context, req CandyMachineDeployRequest) (*CandyMachineDeployResult, error) {
+
+	feePayer := m.feePayer
+
+	if err := validateCandyMachine(req); err != nil {
+		return nil, err
+	}
+	if req.Treasury == (common.PublicKey{}) {
+		req.Treasury = feePayer.PublicKey
+	}
+
+	collectionMetadataPubkey, err := token_metadata.GetTokenMetaPubkey(req.Collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid collection metadata: %w", err)
+	}
+	collectionMasterEditionPubkey, err := token_metadata.GetMasterEdition(req.Collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid collection master edition: %w", err)
+	}
+	collectionInfo, err := m.client.GetAccountInfoWithConfig(ctx, collectionMetadataPubkey.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection metadata info: %w", err)
+	}
+	collectionMetadata, err := token_metadata.MetadataDeserialize(collectionInfo.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse collection metadata: %w", err)
+	}
+	if collectionMetadata.UpdateAuthority != feePayer.PublicKey {
+		return nil, fmt.Errorf("the fee payer is not the update authority of collection %v", req.Collection.ToBase58())
+	}
+
+	candyMachineAccount := types.NewAccount()
+	authorityPDA, _, err := common.FindProgramAddress([][]byte{[]byte("candy_machine"), candyMachineAccount.PublicKey.Bytes()}, CandyMachineProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid candy machine authority: %w", err)
+	}
+	collectionDelegate, err := candyMachineCollectionDelegate(candyMachine{version: 1, collectionMint: req.Collection}, feePayer.PublicKey, authorityPDA)
+	if err != nil {
+		return nil, err
+	}
+
+	size := candyMachineSpace(uint64(len(req.Items)), candyMachineNameLength+candyMachineURILength, false)
+	rent, err := m.client.GetMinimumBalanceForRentExemption(ctx, uint64(size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candy machine rent: %w", err)
+	}
+
+	args, err := borsh.Serialize(struct {
+		ItemsAvailable       uint64
+		Symbol               string
+		SellerFeeBasisPoints uint16
+		MaxSupply            uint64
+		IsMutable            bool
+		Creators             []token_metadata.Creator
+	}{
+		ItemsAvailable:       uint64(len(req.Items)),
+		Symbol:               req.Symbol,
+		SellerFeeBasisPoints: req.SellerFeeBasisPoints,
+		IsMutable:            req.Mutable,
+		Creators:             append([]token_metadata.Creator{}, req.Creators...),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize candy machine data: %w", err)
+	}
+	discriminator := anchorDiscriminator("initialize_v2")
+	// no config line settings, no hidden settings, then the token standard
+	data := append(append(discriminator[:], args...), 0, 0, byte(token_metadata.NonFungible))
+
+	result := &CandyMachineDeployResult{CandyMachine: candyMachineAccount.PublicKey}
+
+	initSig, err := m.sendCandyMachineTx(ctx, []types.Account{feePayer, candyMachineAccount},
+		system.CreateAccount(system.CreateAccountParam{
+			From:     feePayer.PublicKey,
+			New:      candyMachineAccount.PublicKey,
+			Owner:    CandyMachineProgramID,
+			Lamports: rent,
+			Space:    uint64(size),
+		}),
+		types.Instruction{
+			ProgramID: CandyMachineProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: candyMachineAccount.PublicKey, IsSigner: false, IsWritable: true},
+				{PubKey: authorityPDA, IsSigner: false, IsWritable: true},
+				{PubKey: feePayer.PublicKey, IsSigner: false, IsWritable: false},    // authority
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: true},      // payer
+				{PubKey: CandyMachineProgramID, IsSigner: false, IsWritable: false}, // rule set
+				{PubKey: collectionMetadataPubkey, IsSigner: false, IsWritable: true},
+				{PubKey: req.Collection, IsSigner: false, IsWritable: false},
+				{PubKey: collectionMasterEditionPubkey, IsSigner: false, IsWritable: false},
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: true}, // collection update authority
+				{PubKey: collectionDelegate, IsSigner: false, IsWritable: true},
+				{PubKey: common.MetaplexTokenMetaProgramID, IsSigner: false, IsWritable: false},
+				{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+				{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+				{PubKey: CandyMachineProgramID, IsSigner: false, IsWritable: false}, // authorization rules program
+				{PubKey: CandyMachineProgramID, IsSigner: false, IsWritable: false}, // authorization rules
+			},
+			Data: data,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize candy machine: %w", err)
+	}
+	if err := m.WaitForConfirmation(ctx, initSig, ConfirmOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to confirm candy machine: %w", err)
+	}
+	result.Signatures = append(result.Signatures, initSig)
+
+	// the lines of each chunk are written at its index; chunks do not
+	// depend on each other, so all are sent before waiting
+	var lineSigs []string
+	for _, chunk := range chunkConfigLines(req.Items) {
+		args, err := borsh.Serialize(struct {
+			Index uint32
+			Lines []CandyMachineItem
+		}{
+			Index: uint32(chunk.index),
+			Lines: chunk.items,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize config lines: %w", err)
+		}
+		discriminator := anchorDiscriminator("add_config_lines")
+		txSig, err := m.sendCandyMachineTx(ctx, []types.Account{feePayer}, types.Instruction{
+			ProgramID: CandyMachineProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: candyMachineAccount.PublicKey, IsSigner: false, IsWritable: true},
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // authority
+			},
+			Data: append(discriminator[:], args...),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add config lines from item %v: %w", chunk.index+1, err)
+		}
+		lineSigs = append(lineSigs, txSig)
+	}
+	for _, txSig := range lineSigs {
+		if err := m.WaitForConfirmation(ctx, txSig, ConfirmOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to confirm config lines: %w", err)
+		}
+	}
+	result.Signatures = append(result.Signatures, lineSigs...)
+
+	base := types.NewAccount()
+	candyGuard, _, err := common.FindProgramAddress([][]byte{[]byte("candy_guard"), base.PublicKey.Bytes()}, CandyGuardProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid candy guard: %w", err)
+	}
+	guardData := encodeCandyGuard(req)
+	initialize := anchorDiscriminator("initialize")
+	wrap := anchorDiscriminator("wrap")
+
+	guardSig, err := m.sendCandyMachineTx(ctx, []types.Account{feePayer, base},
+		types.Instruction{
+			ProgramID: CandyGuardProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: candyGuard, IsSigner: false, IsWritable: true},
+				{PubKey: base.PublicKey, IsSigner: true, IsWritable: false},
+				{PubKey: feePayer.PublicKey, IsSigner: false, IsWritable: false}, // authority
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: true},   // payer
+				{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			},
+			// the guard data as a borsh Vec<u8>
+			Data: append(binary.LittleEndian.AppendUint32(initialize[:], uint32(len(guardData))), guardData...),
+		},
+		types.Instruction{
+			ProgramID: CandyGuardProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: candyGuard, IsSigner: false, IsWritable: false},
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // candy guard authority
+				{PubKey: candyMachineAccount.PublicKey, IsSigner: false, IsWritable: true},
+				{PubKey: CandyMachineProgramID, IsSigner: false, IsWritable: false},
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // candy machine authority
+			},
+			Data: wrap[:],
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create candy guard: %w", err)
+	}
+	if err := m.WaitForConfirmation(ctx, guardSig, ConfirmOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to confirm candy guard: %w", err)
+	}
+	result.CandyGuard = candyGuard
+	result.Signatures = append(result.Signatures, guardSig)
+
+	return result, nil
+}
+
+// sendCandyMachineTx sends one transaction of a deployment, paid by the
+// fee payer.
+func (m *Minter) sendCandyMachineTx(ctx context.Context, signers []types.Account, instructions ...types.Instruction) (string, error) {
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: signers,
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        m.feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions:    instructions,
+		}),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to send tx: %w", err)
+	}
+	return txSig, nil
+}
+
+func validateCandyMachine(req CandyMachineDeployRequest) error {
+	if len(req.Items) == 0 {
+		return errors.New("a candy machine needs at least one item")
+	}
+	for i, item := range req.Items {
+		if item.Name == "" || item.URI == "" {
+			return fmt.Errorf("item %v: name and uri are required", i+1)
+		}
+		if len(item.Name) > candyMachineNameLength {
+			return fmt.Errorf("item %v: name %q is %v bytes, over the %v byte limit", i+1, item.Name, len(item.Name), candyMachineNameLength)
+		}
+		if len(item.URI) > candyMachineURILength {
+			return fmt.Errorf("item %v: uri is %v bytes, over the %v byte limit", i+1, len(item.URI), candyMachineURILength)
+		}
+	}
+	if len(req.Symbol) > candyMachineSymbolLength {
+		return fmt.Errorf("symbol %q is %v bytes, over the %v byte limit", req.Symbol, len(req.Symbol), candyMachineSymbolLength)
+	}
+	if len(req.Creators) > MaxCreators-1 {
+		return fmt.Errorf("%v creators given, a candy machine allows at most %v", len(req.Creators), MaxCreators-1)
+	}
+	if err := validateRoyalties(req.Creators, req.SellerFeeBasisPoints); err != nil {
+		return err
+	}
+	if !req.StartDate.IsZero() && !req.EndDate.IsZero() && !req.EndDate.After(req.StartDate) {
+		return errors.New("the end date must be after the start date")
+	}
+	return nil
+}
+
+type configLineChunk struct {
+	index int
+	items []CandyMachineItem
+}
+
+// chunkConfigLines splits items into add_config_lines calls of at most
+// candyMachineConfigLineBytes of names and URIs each.
+func chunkConfigLines(items []CandyMachineItem) []configLineChunk {
+	var chunks []configLineChunk
+	start, size := 0, 0
+	for i, item := range items {
+		// both strings are prefixed with their u32 length
+		line := 8 + len(item.Name) + len(item.URI)
+		if i > start && size+line > candyMachineConfigLineBytes {
+			chunks = append(chunks, configLineChunk{index: start, items: items[start:i]})
+			start, size = i, 0
+		}
+		size += line
+	}
+	return append(chunks, configLineChunk{index: start, items: items[start:]})
+}
+
+// encodeCandyGuard encodes the default guard set of req as a Candy Guard
+// stores it: the feature bits, the data of each guard in bit order, then
+// the number of groups.
+func encodeCandyGuard(req CandyMachineDeployRequest) []byte {
+	var features uint64
+	var guards []byte
+	if req.BotTax > 0 {
+		features |= 1 << 0
+		guards = binary.LittleEndian.AppendUint64(guards, req.BotTax)
+		guards = append(guards, 1) // only tax when the mint is the last instruction
+	}
+	if req.Price > 0 {
+		features |= 1 << 1
+		guards = binary.LittleEndian.AppendUint64(guards, req.Price)
+		guards = append(guards, req.Treasury.Bytes()...)
+	}
+	if !req.StartDate.IsZero() {
+		features |= 1 << 3
+		guards = binary.LittleEndian.AppendUint64(guards, uint64(req.StartDate.Unix()))
+	}
+	if !req.EndDate.IsZero() {
+		features |= 1 << 7
+		guards = binary.LittleEndian.AppendUint64(guards, uint64(req.EndDate.Unix()))
+	}
+	if req.MintLimit > 0 {
+		features |= 1 << 9
+		guards = append(guards, 1) // limit id
+		guards = binary.LittleEndian.AppendUint16(guards, req.MintLimit)
+	}
+
+	data := binary.LittleEndian.AppendUint64(nil, features)
+	data = append(data, guards...)
+	return binary.LittleEndian.AppendUint32(data, 0)
+}
+
+type CandyMachineMintRequest struct {
+	CandyMachine common.PublicKey
+	// Buyer pays the guards and receives the NFT; the zero account means
+	// the fee payer.
+	Buyer types.Account
+}
+
+// candyMachine is the part of a Candy Machine Core account a mint needs.
+type candyMachine struct {
+	version        byte
+	tokenStandard  token_metadata.TokenStandard
+	mintAuthority  common.PublicKey
+	collectionMint common.PublicKey
+	itemsRedeemed  uint64
+	itemsAvailable uint64
+	// ruleSet is the rule set a candy machine of programmable NFTs stores
+	// after its config lines, if any.
+	ruleSet common.PublicKey
+}
+
+// candyGuardSet is the default guard set of a Candy Guard, limited to the
+// guards in candyGuardSizes.
+type candyGuardSet struct {
+	botTax         bool
+	solPayment     *common.PublicKey // destination
+	startDate      *int64
+	endDate        *int64
+	mintLimit      *candyMintLimit
+	redeemedAmount *uint64
+	addressGate    *common.PublicKey
+}
+
+type candyMintLimit struct {
+	id    uint8
+	limit uint16
+}
+
+// MintFromCandyMachine mints the next item of a Candy Machine v3 wrapped by
+// a Candy Guard, as a buyer of the drop rather than its authority: the
+// Candy Guard mint_v2 instruction creates the mint, metadata, master edition
+// and the buyer's ATA and verifies the item in the collection. Only the
+// default guard set is used, and only with the botTax, solPayment,
+// startDate, endDate, mintLimit, redeemedAmount and addressGate guards;
+// those that can be checked up front are, so the bot tax is not charged
+// for a mint bound to fail. Candy machines of programmable NFTs with a rule
+// set fail with ErrUnsupportedRuleSet.
+func (m *Minter) MintFromCandyMachine(ctx context.Context, req CandyMachineMintRequest) (*MintResult, error) {
+
+	feePayer := m.feePayer
+	buyer := req.Buyer
+	if buyer.PublicKey == (common.PublicKey{}) {
+		buyer = feePayer
+	}
+
+	machineInfo, err := m.client.GetAccountInfoWithConfig(ctx, req.CandyMachine.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candy machine info: %w", err)
+	}
+	if machineInfo.Owner != CandyMachineProgramID {
+		return nil, fmt.Errorf("%v is not a candy machine", req.CandyMachine.ToBase58())
+	}
+	machine, err := parseCandyMachine(machineInfo.Data)
+	if err != nil {
+		return nil, err
+	}
+	if machine.itemsRedeemed >= machine.itemsAvailable {
+		return nil, fmt.Errorf("%w: %v of %v", ErrCandyMachineEmpty, machine.itemsRedeemed, machine.itemsAvailable)
+	}
+	if machine.ruleSet != (common.PublicKey{}) {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedRuleSet, machine.ruleSet.ToBase58())
+	}
+
+	// a wrapped candy machine's mint authority is its candy guard
+	candyGuard := machine.mintAuthority
+	guardInfo, err := m.client.GetAccountInfoWithConfig(ctx, candyGuard.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candy guard info: %w", err)
+	}
+	if guardInfo.Owner != CandyGuardProgramID {
+		return nil, fmt.Errorf("candy machine %v is not wrapped by a candy guard", req.CandyMachine.ToBase58())
+	}
+	guards, err := parseCandyGuard(guardInfo.Data)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := m.checkCandyGuards(ctx, guards, buyer.PublicKey, candyGuard, req.CandyMachine, machine)
+	if err != nil {
+		return nil, err
+	}
+
+	mint := types.NewAccount()
+	instruction, ata, err := m.candyMachineMintInstruction(ctx, req.CandyMachine, candyGuard, machine, feePayer.PublicKey, buyer.PublicKey, mint.PublicKey, remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	signers := []types.Account{feePayer, mint}
+	if buyer.PublicKey != feePayer.PublicKey {
+		signers = append(signers, buyer)
+	}
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions: []types.Instruction{
+				compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+					Units: candyMachineComputeUnits,
+				}),
+				instruction,
+			},
+		}),
+		Signers: signers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &MintResult{Signature: txSig, Mint: mint.PublicKey, TokenAccount: ata}, nil
+}
+
+// checkCandyGuards fails the mint a guard would reject and returns the
+// remaining accounts of the guards, in guard order.
+func (m *Minter) checkCandyGuards(ctx context.Context, guards candyGuardSet, buyer, candyGuard, candyMachine common.PublicKey, machine candyMachine) ([]types.AccountMeta, error) {
+
+	var remaining []types.AccountMeta
+
+	if guards.solPayment != nil {
+		remaining = append(remaining, types.AccountMeta{PubKey: *guards.solPayment, IsSigner: false, IsWritable: true})
+	}
+
+	if guards.startDate != nil || guards.endDate != nil {
+		now, err := m.clusterTime(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if guards.startDate != nil && now < *guards.startDate {
+			return nil, fmt.Errorf("%w: the mint starts at %v", ErrGuardFailed, time.Unix(*guards.startDate, 0).UTC().Format(time.RFC3339))
+		}
+		if guards.endDate != nil && now >= *guards.endDate {
+			return nil, fmt.Errorf("%w: the mint ended at %v", ErrGuardFailed, time.Unix(*guards.endDate, 0).UTC().Format(time.RFC3339))
+		}
+	}
+
+	if limit := guards.mintLimit; limit != nil {
+		counter, _, err := common.FindProgramAddress(
+			[][]byte{[]byte("mint_limit"), {limit.id}, buyer.Bytes(), candyGuard.Bytes(), candyMachine.Bytes()},
+			CandyGuardProgramID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find a valid mint counter: %w", err)
+		}
+		counterInfo, err := m.client.GetAccountInfoWithConfig(ctx, counter.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mint counter info: %w", err)
+		}
+		// discriminator, then the count
+		if counterInfo.Owner == CandyGuardProgramID && len(counterInfo.Data) >= 10 {
+			if count := binary.LittleEndian.Uint16(counterInfo.Data[8:10]); count >= limit.limit {
+				return nil, fmt.Errorf("%w: %v already minted its limit of %v", ErrGuardFailed, buyer.ToBase58(), limit.limit)
+			}
+		}
+		remaining = append(remaining, types.AccountMeta{PubKey: counter, IsSigner: false, IsWritable: true})
+	}
+
+	if guards.redeemedAmount != nil && machine.itemsRedeemed >= *guards.redeemedAmount {
+		return nil, fmt.Errorf("%w: %v items redeemed, the limit is %v", ErrGuardFailed, machine.itemsRedeemed, *guards.redeemedAmount)
+	}
+
+	if guards.addressGate != nil && *guards.addressGate != buyer {
+		return nil, fmt.Errorf("%w: only %v may mint", ErrGuardFailed, guards.addressGate.ToBase58())
+	}
+
+	return remaining, nil
+}
+
+// clusterTime is the block time of the current slot, which the start and
+// end date guards compare with.
+func (m *Minter) clusterTime(ctx context.Context) (int64, error) {
+	slot, err := m.client.GetSlotWithConfig(ctx, client.GetSlotConfig{Commitment: m.Commitment})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get slot: %w", err)
+	}
+	blockTime, err := m.client.GetBlockTime(ctx, slot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block time: %w", err)
+	}
+	if blockTime == nil {
+		return 0, fmt.Errorf("no block time for slot %v", slot)
+	}
+	return *blockTime, nil
+}
+
+// candyMachineMintInstruction builds the Candy Guard mint_v2 instruction
+// and returns it with the buyer's ATA. Absent optional accounts are passed
+// as the Candy Guard program, as Anchor expects.
+func (m *Minter) candyMachineMintInstruction(ctx context.Context, candyMachine, candyGuard common.PublicKey, machine candyMachine, payer, buyer, mint common.PublicKey, remaining []types.AccountMeta) (types.Instruction, common.PublicKey, error) {
+
+	authorityPDA, _, err := common.FindProgramAddress([][]byte{[]byte("candy_machine"), candyMachine.Bytes()}, CandyMachineProgramID)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to find a valid candy machine authority: %w", err)
+	}
+	ata, _, err := common.FindAssociatedTokenAddress(buyer, mint)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to find a valid ata: %w", err)
+	}
+	metadataPubkey, err := token_metadata.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	masterEditionPubkey, err := token_metadata.GetMasterEdition(mint)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to find a valid master edition: %w", err)
+	}
+
+	tokenRecord := CandyGuardProgramID
+	if machine.tokenStandard == token_metadata.ProgrammableNonFungible {
+		tokenRecord, err = tokenRecordAddress(mint, ata)
+		if err != nil {
+			return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to find a valid token record: %w", err)
+		}
+	}
+
+	// collection
+	collectionMetadataPubkey, err := token_metadata.GetTokenMetaPubkey(machine.collectionMint)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to find a valid collection metadata: %w", err)
+	}
+	collectionMasterEditionPubkey, err := token_metadata.GetMasterEdition(machine.collectionMint)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to find a valid collection master edition: %w", err)
+	}
+	collectionInfo, err := m.client.GetAccountInfoWithConfig(ctx, collectionMetadataPubkey.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to get collection metadata info: %w", err)
+	}
+	collectionMetadata, err := token_metadata.MetadataDeserialize(collectionInfo.Data)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("failed to parse collection metadata: %w", err)
+	}
+	// a programmable candy machine without a rule set of its own uses the
+	// collection's
+	if config := collectionMetadata.ProgrammableConfig; machine.tokenStandard == token_metadata.ProgrammableNonFungible && config != nil && config.V1.RuleSet != nil {
+		return types.Instruction{}, common.PublicKey{}, fmt.Errorf("%w: %v", ErrUnsupportedRuleSet, config.V1.RuleSet.ToBase58())
+	}
+	collectionDelegate, err := candyMachineCollectionDelegate(machine, collectionMetadata.UpdateAuthority, authorityPDA)
+	if err != nil {
+		return types.Instruction{}, common.PublicKey{}, err
+	}
+
+	// mint args (no guard here takes any), then no group label
+	discriminator := anchorDiscriminator("mint_v2")
+	data := append(discriminator[:], 0, 0, 0, 0, 0)
+
+	accounts := []types.AccountMeta{
+		{PubKey: candyGuard, IsSigner: false, IsWritable: false},
+		{PubKey: CandyMachineProgramID, IsSigner: false, IsWritable: false},
+		{PubKey: candyMachine, IsSigner: false, IsWritable: true},
+		{PubKey: authorityPDA, IsSigner: false, IsWritable: true},
+		{PubKey: payer, IsSigner: true, IsWritable: true},
+		{PubKey: buyer, IsSigner: true, IsWritable: true},
+		{PubKey: mint, IsSigner: true, IsWritable: true},
+		{PubKey: buyer, IsSigner: true, IsWritable: false}, // mint authority
+		{PubKey: metadataPubkey, IsSigner: false, IsWritable: true},
+		{PubKey: masterEditionPubkey, IsSigner: false, IsWritable: true},
+		{PubKey: ata, IsSigner: false, IsWritable: true},
+		{PubKey: tokenRecord, IsSigner: false, IsWritable: tokenRecord != CandyGuardProgramID},
+		{PubKey: collectionDelegate, IsSigner: false, IsWritable: false},
+		{PubKey: machine.collectionMint, IsSigner: false, IsWritable: false},
+		{PubKey: collectionMetadataPubkey, IsSigner: false, IsWritable: true},
+		{PubKey: collectionMasterEditionPubkey, IsSigner: false, IsWritable: false},
+		{PubKey: collectionMetadata.UpdateAuthority, IsSigner: false, IsWritable: false},
+		{PubKey: common.MetaplexTokenMetaProgramID, IsSigner: false, IsWritable: false},
+		{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+		{PubKey: common.SPLAssociatedTokenAccountProgramID, IsSigner: false, IsWritable: false},
+		{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+		{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+		{PubKey: common.SysVarSlotHashesPubkey, IsSigner: false, IsWritable: false},
+		{PubKey: CandyGuardProgramID, IsSigner: false, IsWritable: false}, // authorization rules program
+		{PubKey: CandyGuardProgramID, IsSigner: false, IsWritable: false}, // authorization rules
+	}
+
+	return types.Instruction{
+		ProgramID: CandyGuardProgramID,
+		Accounts:  append(accounts, remaining...),
+		Data:      data,
+	}, ata, nil
+}
+
+// candyMachineCollectionDelegate derives the account letting the candy
+// machine verify its items in the collection: a collection authority
+// record for a V1 candy machine, a metadata delegate record for a V2 one.
+func candyMachineCollectionDelegate(machine candyMachine, updateAuthority, authorityPDA common.PublicKey) (common.PublicKey, error) {
+	seeds := [][]byte{
+		[]byte("metadata"),
+		common.MetaplexTokenMetaProgramID.Bytes(),
+		machine.collectionMint.Bytes(),
+		[]byte("collection_authority"),
+		authorityPDA.Bytes(),
+	}
+	if machine.version > 0 {
+		seeds = [][]byte{
+			[]byte("metadata"),
+			common.MetaplexTokenMetaProgramID.Bytes(),
+			machine.collectionMint.Bytes(),
+			[]byte("collection_delegate"),
+			updateAuthority.Bytes(),
+			authorityPDA.Bytes(),
+		}
+	}
+	address, _, err := common.FindProgramAddress(seeds, common.MetaplexTokenMetaProgramID)
+	if err != nil {
+		return common.PublicKey{}, fmt.Errorf("failed to find a valid collection delegate: %w", err)
+	}
+	return address, nil
+}
+
+// parseCandyMachine reads the fixed-size head of a Candy Machine Core
+// account and, for programmable NFTs, the rule set after its config lines.
+func parseCandyMachine(data []byte) (candyMachine, error) {
+	discriminator := anchorAccountDiscriminator("CandyMachine")
+	if len(data) < candyMachineItemsAvailableOffset+8 || string(data[:8]) != string(discriminator[:]) {
+		return candyMachine{}, errors.New("invalid candy machine account")
+	}
+	machine := candyMachine{
+		version:        data[candyMachineVersionOffset],
+		tokenStandard:  token_metadata.TokenStandard(data[candyMachineTokenStandardOffset]),
+		mintAuthority:  common.PublicKeyFromBytes(data[candyMachineMintAuthorityOffset : candyMachineMintAuthorityOffset+32]),
+		collectionMint: common.PublicKeyFromBytes(data[candyMachineCollectionMintOffset : candyMachineCollectionMintOffset+32]),
+		itemsRedeemed:  binary.LittleEndian.Uint64(data[candyMachineItemsRedeemedOffset:]),
+		itemsAvailable: binary.LittleEndian.Uint64(data[candyMachineItemsAvailableOffset:]),
+	}
+	if machine.tokenStandard != token_metadata.ProgrammableNonFungible {
+		return machine, nil
+	}
+
+	lineSize, hidden, err := candyMachineLines(data[candyMachineItemsAvailableOffset+8:])
+	if err != nil {
+		return candyMachine{}, err
+	}
+	// a set flag, then the rule set
+	offset := candyMachineSpace(machine.itemsAvailable, lineSize, hidden)
+	if len(data) >= offset+33 && data[offset] == 1 {
+		machine.ruleSet = common.PublicKeyFromBytes(data[offset+1 : offset+33])
+	}
+	return machine, nil
+}
+
+// candyMachineLines reads the rest of a candy machine's data, from its
+// symbol on, for the size of its config lines and whether it uses hidden
+// settings instead.
+func candyMachineLines(data []byte) (int, bool, error) {
+	r := bytes.NewReader(data)
+	var err error
+	u8 := func() (v uint8) {
+		if err == nil {
+			err = binary.Read(r, binary.LittleEndian, &v)
+		}
+		return v
+	}
+	u32 := func() (v uint32) {
+		if err == nil {
+			err = binary.Read(r, binary.LittleEndian, &v)
+		}
+		return v
+	}
+	skip := func(n int64) {
+		if err == nil {
+			_, err = r.Seek(n, io.SeekCurrent)
+		}
+	}
+
+	skip(int64(u32()))                // symbol
+	skip(2 + 8 + 1)                   // seller fee basis points, max supply, is mutable
+	skip(int64(u32()) * (32 + 1 + 1)) // creators
+
+	lineSize := candyMachineNameLength + candyMachineURILength
+	if u8() == 1 {
+		// prefix name, name length, prefix uri, uri length, is sequential
+		skip(int64(u32()))
+		nameLength := u32()
+		skip(int64(u32()))
+		lineSize = int(nameLength + u32())
+		skip(1)
+	}
+	hidden := u8() == 1
+	if err != nil {
+		return 0, false, fmt.Errorf("truncated candy machine account: %w", err)
+	}
+	return lineSize, hidden, nil
+}
+
+// candyMachineSpace is the size of a candy machine account of itemsAvailable
+// config lines of lineSize bytes, before the rule set of a programmable
+// one: the hidden section, the number of loaded lines, the lines, a bitmask
+// of the loaded lines and the mint order. Hidden settings store no lines.
+func candyMachineSpace(itemsAvailable uint64, lineSize int, hidden bool) int {
+	if hidden {
+		return candyMachineHiddenSection
+	}
+	n := int(itemsAvailable)
+	return candyMachineHiddenSection + 4 + n*lineSize + n/8 + 1 + n*4
+}
+
+// parseCandyGuard reads the default guard set of a Candy Guard account: a
+// u64 of feature bits, then the data of each enabled guard in bit order,
+// then the number of groups.
+func parseCandyGuard(data []byte) (candyGuardSet, error) {
+	discriminator := anchorAccountDiscriminator("CandyGuard")
+	if len(data) < candyGuardDataOffset+8 || string(data[:8]) != string(discriminator[:]) {
+		return candyGuardSet{}, errors.New("invalid candy guard account")
+	}
+	features := binary.LittleEndian.Uint64(data[candyGuardDataOffset:])
+	data = data[candyGuardDataOffset+8:]
+
+	var guards candyGuardSet
+	for bit := 0; bit < 64; bit++ {
+		if features&(1<<bit) == 0 {
+			continue
+		}
+		size, ok := candyGuardSizes[bit]
+		if !ok {
+			name := fmt.Sprintf("guard %v", bit)
+			if bit < len(candyGuardNames) {
+				name = candyGuardNames[bit]
+			}
+			return candyGuardSet{}, fmt.Errorf("%w: %v", ErrUnsupportedGuard, name)
+		}
+		if len(data) < size {
+			return candyGuardSet{}, errors.New("truncated candy guard account")
+		}
+		value := data[:size]
+		data = data[size:]
+
+		switch bit {
+		case 0:
+			guards.botTax = true
+		case 1:
+			destination := common.PublicKeyFromBytes(value[8:40])
+			guards.solPayment = &destination
+		case 3:
+			start := int64(binary.LittleEndian.Uint64(value))
+			guards.startDate = &start
+		case 7:
+			end := int64(binary.LittleEndian.Uint64(value))
+			guards.endDate = &end
+		case 9:
+			guards.mintLimit = &candyMintLimit{id: value[0], limit: binary.LittleEndian.Uint16(value[1:3])}
+		case 11:
+			maximum := binary.LittleEndian.Uint64(value)
+			guards.redeemedAmount = &maximum
+		case 12:
+			address := common.PublicKeyFromBytes(value)
+			guards.addressGate = &address
+		}
+	}
+
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) > 0 {
+		return candyGuardSet{}, fmt.Errorf("%w: guard groups", ErrUnsupportedGuard)
+	}
+	return guards, nil
+}
+
+// anchorAccountDiscriminator is the 8-byte prefix of Anchor accounts of
+// type name.
+func anchorAccountDiscriminator(name string) [8]byte {
+	var discriminator [8]byte
+	digest := sha256.Sum256([]byte("account:" + name))
+	copy(discriminator[:], digest[:8])
+	return discriminator
+}
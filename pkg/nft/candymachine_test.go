@@ -0,0 +1,316 @@
+package nft
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+)
+
+var (
+	candyGuardKey   = common.PublicKeyFromString("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM")
+	candyCollection = common.PublicKeyFromString("8csJmhaFLM7Ha8k1VYXtYw53eCRn9BbNRfoXD5D8XMWC")
+	candyTreasury   = common.PublicKeyFromString("So11111111111111111111111111111111111111112")
+	candyRuleSet    = common.PublicKeyFromString("Vote111111111111111111111111111111111111111")
+)
+
+// packCandyMachine hand-encodes a Candy Machine Core account of the given
+// token standard and items, followed by data, the encoded variable part of
+// its candy machine data from the symbol on.
+func packCandyMachine(tokenStandard token_metadata.TokenStandard, redeemed, available uint64, data []byte) []byte {
+	discriminator := anchorAccountDiscriminator("CandyMachine")
+	account := append([]byte{}, discriminator[:]...)
+	account = append(account, 1, byte(tokenStandard), 0, 0, 0, 0, 0, 0)
+	account = append(account, make([]byte, 32)...) // authority
+	account = append(account, candyGuardKey.Bytes()...)
+	account = append(account, candyCollection.Bytes()...)
+	account = binary.LittleEndian.AppendUint64(account, redeemed)
+	account = binary.LittleEndian.AppendUint64(account, available)
+	return append(account, data...)
+}
+
+// packCandyMachineData encodes a symbol, royalties, one creator and the
+// given config line and hidden settings options.
+func packCandyMachineData(configLineSettings, hiddenSettings []byte) []byte {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 3)
+	data = append(data, "SYM"...)
+	data = binary.LittleEndian.AppendUint16(data, 500)
+	data = binary.LittleEndian.AppendUint64(data, 0)
+	data = append(data, 1)
+	data = binary.LittleEndian.AppendUint32(data, 1)
+	data = append(data, candyTreasury.Bytes()...)
+	data = append(data, 0, 100)
+	data = append(data, configLineSettings...)
+	return append(data, hiddenSettings...)
+}
+
+func TestParseCandyMachine(t *testing.T) {
+	data := packCandyMachine(token_metadata.NonFungible, 7, 10, make([]byte, 64))
+
+	machine, err := parseCandyMachine(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := candyMachine{
+		version:        1,
+		tokenStandard:  token_metadata.NonFungible,
+		mintAuthority:  candyGuardKey,
+		collectionMint: candyCollection,
+		itemsRedeemed:  7,
+		itemsAvailable: 10,
+	}
+	if machine != want {
+		t.Errorf("got %+v, want %+v", machine, want)
+	}
+
+	if _, err := parseCandyMachine(data[:100]); err == nil {
+		t.Error("a truncated candy machine parsed")
+	}
+	other := append([]byte{}, data...)
+	other[0] ^= 1
+	if _, err := parseCandyMachine(other); err == nil {
+		t.Error("a candy machine with another discriminator parsed")
+	}
+}
+
+func TestParseCandyMachineRuleSet(t *testing.T) {
+	var settings []byte
+	settings = append(settings, 1)
+	settings = binary.LittleEndian.AppendUint32(settings, 2)
+	settings = append(settings, "#$"...)
+	settings = binary.LittleEndian.AppendUint32(settings, 4)
+	settings = binary.LittleEndian.AppendUint32(settings, 0)
+	settings = binary.LittleEndian.AppendUint32(settings, 20)
+	settings = append(settings, 0)
+
+	for name, tt := range map[string]struct {
+		data  []byte
+		space int
+	}{
+		"config lines":         {data: packCandyMachineData([]byte{0}, []byte{0}), space: candyMachineSpace(10, 232, false)},
+		"config line settings": {data: packCandyMachineData(settings, []byte{0}), space: candyMachineSpace(10, 24, false)},
+		"hidden settings":      {data: packCandyMachineData([]byte{0}, []byte{1}), space: candyMachineHiddenSection},
+	} {
+		data := packCandyMachine(token_metadata.ProgrammableNonFungible, 0, 10, tt.data)
+		data = append(data, make([]byte, tt.space-len(data))...)
+
+		machine, err := parseCandyMachine(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if machine.ruleSet != (common.PublicKey{}) {
+			t.Errorf("%v: rule set %v without one stored", name, machine.ruleSet.ToBase58())
+		}
+
+		data = append(append(data, 1), candyRuleSet.Bytes()...)
+		if machine, err = parseCandyMachine(data); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if machine.ruleSet != candyRuleSet {
+			t.Errorf("%v: rule set = %v, want %v", name, machine.ruleSet.ToBase58(), candyRuleSet.ToBase58())
+		}
+	}
+
+	if _, err := parseCandyMachine(packCandyMachine(token_metadata.ProgrammableNonFungible, 0, 10, []byte{3, 0, 0, 0})); err == nil {
+		t.Error("a truncated programmable candy machine parsed")
+	}
+}
+
+func TestCandyMachineSpace(t *testing.T) {
+	// the hidden section, 4 bytes of line count, 5 lines of 232 bytes, a
+	// 1 byte bitmask and 5 u32 mint indices
+	if got, want := candyMachineSpace(5, 232, false), 850+4+5*232+1+5*4; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := candyMachineSpace(5, 232, true); got != candyMachineHiddenSection {
+		t.Errorf("hidden settings: got %v, want %v", got, candyMachineHiddenSection)
+	}
+}
+
+// packCandyGuard hand-encodes a Candy Guard account with the given default
+// guard set data and group count.
+func packCandyGuard(features uint64, guards []byte, groups uint32) []byte {
+	discriminator := anchorAccountDiscriminator("CandyGuard")
+	data := append([]byte{}, discriminator[:]...)
+	data = append(data, make([]byte, 32+1+32)...) // base, bump, authority
+	data = binary.LittleEndian.AppendUint64(data, features)
+	data = append(data, guards...)
+	return binary.LittleEndian.AppendUint32(data, groups)
+}
+
+func TestParseCandyGuard(t *testing.T) {
+	var guards []byte
+	guards = binary.LittleEndian.AppendUint64(guards, 10_000_000) // botTax
+	guards = append(guards, 1)
+	guards = binary.LittleEndian.AppendUint64(guards, 1_000_000_000) // solPayment
+	guards = append(guards, candyTreasury.Bytes()...)
+	guards = binary.LittleEndian.AppendUint64(guards, 1_700_000_000) // startDate
+	guards = append(guards, 2)                                       // mintLimit
+	guards = binary.LittleEndian.AppendUint16(guards, 3)
+	guards = append(guards, candyCollection.Bytes()...) // addressGate
+	features := uint64(1<<0 | 1<<1 | 1<<3 | 1<<9 | 1<<12)
+
+	set, err := parseCandyGuard(packCandyGuard(features, guards, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.botTax {
+		t.Error("no bot tax")
+	}
+	if set.solPayment == nil || *set.solPayment != candyTreasury {
+		t.Errorf("sol payment = %v", set.solPayment)
+	}
+	if set.startDate == nil || *set.startDate != 1_700_000_000 || set.endDate != nil {
+		t.Errorf("start date = %v, end date = %v", set.startDate, set.endDate)
+	}
+	if set.mintLimit == nil || *set.mintLimit != (candyMintLimit{id: 2, limit: 3}) {
+		t.Errorf("mint limit = %+v", set.mintLimit)
+	}
+	if set.addressGate == nil || *set.addressGate != candyCollection || set.redeemedAmount != nil {
+		t.Errorf("address gate = %v, redeemed amount = %v", set.addressGate, set.redeemedAmount)
+	}
+
+	if set, err := parseCandyGuard(packCandyGuard(0, nil, 0)); err != nil || set != (candyGuardSet{}) {
+		t.Errorf("no guards: %+v, %v", set, err)
+	}
+
+	for name, tt := range map[string]struct {
+		data        []byte
+		unsupported bool
+	}{
+		"token payment":       {data: packCandyGuard(1<<2, make([]byte, 72), 0), unsupported: true},
+		"unknown guard":       {data: packCandyGuard(1<<40, nil, 0), unsupported: true},
+		"groups":              {data: packCandyGuard(0, nil, 1), unsupported: true},
+		"truncated guard":     {data: packCandyGuard(1<<1, make([]byte, 20), 0)[:candyGuardDataOffset+8+20]},
+		"wrong discriminator": {data: append(make([]byte, 8), packCandyGuard(0, nil, 0)[8:]...)},
+	} {
+		_, err := parseCandyGuard(tt.data)
+		if err == nil {
+			t.Errorf("%v: parsed", name)
+			continue
+		}
+		if errors.Is(err, ErrUnsupportedGuard) != tt.unsupported {
+			t.Errorf("%v: error = %v", name, err)
+		}
+	}
+}
+
+func TestEncodeCandyGuard(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	end := time.Unix(1_800_000_000, 0)
+	encoded := encodeCandyGuard(CandyMachineDeployRequest{
+		Price:     500_000_000,
+		Treasury:  candyTreasury,
+		BotTax:    10_000_000,
+		StartDate: start,
+		EndDate:   end,
+		MintLimit: 2,
+	})
+
+	// what a deployment stores is what a mint reads back
+	discriminator := anchorAccountDiscriminator("CandyGuard")
+	account := append(append(discriminator[:], make([]byte, 32+1+32)...), encoded...)
+	set, err := parseCandyGuard(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.botTax || set.solPayment == nil || *set.solPayment != candyTreasury {
+		t.Errorf("bot tax = %v, sol payment = %v", set.botTax, set.solPayment)
+	}
+	if set.startDate == nil || *set.startDate != start.Unix() || set.endDate == nil || *set.endDate != end.Unix() {
+		t.Errorf("start date = %v, end date = %v", set.startDate, set.endDate)
+	}
+	if set.mintLimit == nil || set.mintLimit.limit != 2 {
+		t.Errorf("mint limit = %+v", set.mintLimit)
+	}
+	if price := binary.LittleEndian.Uint64(encoded[8+9:]); price != 500_000_000 {
+		t.Errorf("price = %v", price)
+	}
+
+	if free := encodeCandyGuard(CandyMachineDeployRequest{}); len(free) != 8+4 {
+		t.Errorf("no guards encoded as %v bytes", len(free))
+	}
+}
+
+func TestChunkConfigLines(t *testing.T) {
+	var items []CandyMachineItem
+	for i := 0; i < 10; i++ {
+		items = append(items, CandyMachineItem{Name: "Item", URI: strings.Repeat("x", 188)})
+	}
+
+	// 200 bytes a line, 4 lines a chunk
+	chunks := chunkConfigLines(items)
+	if len(chunks) != 3 {
+		t.Fatalf("got %v chunks, want 3", len(chunks))
+	}
+	for i, want := range []struct{ index, lines int }{{0, 4}, {4, 4}, {8, 2}} {
+		if chunks[i].index != want.index || len(chunks[i].items) != want.lines {
+			t.Errorf("chunk %v: index %v with %v lines, want index %v with %v", i+1, chunks[i].index, len(chunks[i].items), want.index, want.lines)
+		}
+	}
+}
+
+func TestValidateCandyMachine(t *testing.T) {
+	item := CandyMachineItem{Name: "Item", URI: "https://x/1.json"}
+	creator := func(share uint8) token_metadata.Creator {
+		return token_metadata.Creator{Address: candyTreasury, Share: share}
+	}
+
+	for name, tt := range map[string]struct {
+		req     CandyMachineDeployRequest
+		wantErr string
+	}{
+		"valid":          {req: CandyMachineDeployRequest{Items: []CandyMachineItem{item}, Creators: []token_metadata.Creator{creator(100)}}},
+		"no items":       {req: CandyMachineDeployRequest{}, wantErr: "at least one item"},
+		"long name":      {req: CandyMachineDeployRequest{Items: []CandyMachineItem{item, {Name: strings.Repeat("n", 33), URI: item.URI}}}, wantErr: "item 2: name"},
+		"long uri":       {req: CandyMachineDeployRequest{Items: []CandyMachineItem{{Name: item.Name, URI: strings.Repeat("u", 201)}}}, wantErr: "item 1: uri"},
+		"long symbol":    {req: CandyMachineDeployRequest{Items: []CandyMachineItem{item}, Symbol: "SYMBOLSYMBOL"}, wantErr: "symbol"},
+		"shares":         {req: CandyMachineDeployRequest{Items: []CandyMachineItem{item}, Creators: []token_metadata.Creator{creator(50)}}, wantErr: "add up to 50"},
+		"too many":       {req: CandyMachineDeployRequest{Items: []CandyMachineItem{item}, Creators: make([]token_metadata.Creator, MaxCreators)}, wantErr: "at most 4"},
+		"ends too early": {req: CandyMachineDeployRequest{Items: []CandyMachineItem{item}, StartDate: time.Unix(2, 0), EndDate: time.Unix(1, 0)}, wantErr: "end date"},
+	} {
+		err := validateCandyMachine(tt.req)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%v: %v", name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%v: got error %v, want %q", name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCandyMachineCollectionDelegate(t *testing.T) {
+	authorityPDA, _, err := common.FindProgramAddress([][]byte{[]byte("candy_machine"), candyGuardKey.Bytes()}, CandyMachineProgramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := [][]byte{[]byte("metadata"), common.MetaplexTokenMetaProgramID.Bytes(), candyCollection.Bytes()}
+
+	for _, tt := range []struct {
+		version byte
+		seeds   [][]byte
+	}{
+		{version: 0, seeds: append(prefix, []byte("collection_authority"), authorityPDA.Bytes())},
+		{version: 1, seeds: append(prefix, []byte("collection_delegate"), candyTreasury.Bytes(), authorityPDA.Bytes())},
+	} {
+		got, err := candyMachineCollectionDelegate(candyMachine{version: tt.version, collectionMint: candyCollection}, candyTreasury, authorityPDA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _, err := common.FindProgramAddress(tt.seeds, common.MetaplexTokenMetaProgramID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("version %v: got %v, want %v", tt.version, got.ToBase58(), want.ToBase58())
+		}
+	}
+}
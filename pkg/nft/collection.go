@@ -0,0 +1,49 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/pkg/pointer"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+type CollectionRequest struct {
+	// Receiver holds the collection NFT; the fee payer stays its update
+	// authority and so the collection authority for item verification.
+	Receiver common.PublicKey
+	Name     string
+	Symbol   string
+	URI      string
+}
+
+// CreateCollection mints a sized collection NFT with a master edition. Its
+// mint is passed as MintRequest.Collection when minting the items.
+func (m *Minter) CreateCollection(ctx context.Context, req CollectionRequest) (*MintResult, error) {
+
+	if !common.IsOnCurve(req.Receiver) {
+		return nil, fmt.Errorf("receiver %v is off curve", req.Receiver.ToBase58())
+	}
+	name, symbol, err := fitMetadataStrings(req.Name, req.Symbol, req.URI, RejectLongNames)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.mintNFT(ctx, nftParams{
+		mint:     types.NewAccount(),
+		receiver: req.Receiver,
+		data: token_metadata.DataV2{
+			Name:                 name,
+			Symbol:               symbol,
+			Uri:                  req.URI,
+			SellerFeeBasisPoints: 0,
+		},
+		maxSupply: pointer.Get[uint64](0),
+		collectionDetails: &token_metadata.CollectionDetails{
+			Enum: 0, // V1, the size is counted up as items are verified
+			V1:   token_metadata.CollectionDetailsV1{Size: 0},
+		},
+	})
+}
@@ -0,0 +1,437 @@
+package nft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/mr-tron/base58"
+	"github.com/near/borsh-go"
+)
+
+var (
+	BubblegumProgramID             = common.PublicKeyFromString("BGUMAp9Gq7iTEuizy4pqaxsTyUCBK68MDfK752saRPUY")
+	SPLAccountCompressionProgramID = common.PublicKeyFromString("cmtDvXumGCrqC1Age74AVPhSRVXJMd8PJS91L8KbNCK")
+	SPLNoopProgramID               = common.PublicKeyFromString("noopb9bkMVfRPU8AsbpTUg8AQkHtKwMYZiFUjNRtMmV")
+)
+
+// ErrCompressedURITemplate is returned for a compressed mint whose URI is a
+// {mint} template.
+var ErrCompressedURITemplate = errors.New("compressed NFTs have no mint address to expand {mint} in the uri with")
+
+type CompressedMintRequest struct {
+	// Tree is a Merkle tree created for Bubblegum whose creator or delegate
+	// is the fee payer, unless the tree is public.
+	Tree     common.PublicKey
+	Receiver common.PublicKey
+	Name     string
+	Symbol   string
+	// URI cannot be a {mint} template, see ErrCompressedURITemplate.
+	URI string
+	// LongNames is how a Name or Symbol over the on-chain limits is handled.
+	LongNames LengthPolicy
+	// Collection is required; the fee payer must be its update authority.
+	Collection           common.PublicKey
+	Creators             []token_metadata.Creator
+	SellerFeeBasisPoints uint16
+	Mutable              bool
+}
+
+type CompressedMintResult struct {
+	Signature string
+	// AssetID is the cNFT's ID, derived from the tree's mint count before
+	// sending; a concurrent mint into the same tree may take that leaf.
+	AssetID common.PublicKey
+}
+
+// treeConfig is the Bubblegum account tracking who may mint into a tree.
+type treeConfig struct {
+	Discriminator     [8]byte
+	TreeCreator       common.PublicKey
+	TreeDelegate      common.PublicKey
+	TotalMintCapacity uint64
+	NumMinted         uint64
+	IsPublic          bool
+}
+
+// metadataArgs is the Bubblegum MetadataArgs, which differs from token
+// metadata's DataV2 in layout.
+type metadataArgs struct {
+	Name                 string
+	Symbol               string
+	Uri                  string
+	SellerFeeBasisPoints uint16
+	PrimarySaleHappened  bool
+	IsMutable            bool
+	EditionNonce         *uint8
+	TokenStandard        *token_metadata.TokenStandard
+	Collection           *token_metadata.Collection
+	Uses                 *token_metadata.Uses
+	TokenProgramVersion  uint8
+	Creators             []token_metadata.Creator
+}
+
+// MintCompressed mints a compressed NFT into a verified collection with the
+// Bubblegum mintToCollectionV1 instruction. The NFT lives as a leaf of the
+// tree, so no mint, metadata or token account rent is paid.
+func (m *Minter) MintCompressed(ctx context.Context, req CompressedMintRequest) (*CompressedMintResult, error) {
+
+	feePayer := m.feePayer
+
+	if req.Collection == (common.PublicKey{}) {
+		return nil, fmt.Errorf("compressed NFTs are minted into a collection, none given")
+	}
+	// the leaf is hashed with the URI as given, and a compressed NFT has no
+	// mint account whose address a template could take
+	if strings.Contains(req.URI, "{mint}") {
+		return nil, ErrCompressedURITemplate
+	}
+	name, symbol, err := fitMetadataStrings(req.Name, req.Symbol, req.URI, req.LongNames)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRoyalties(req.Creators, req.SellerFeeBasisPoints); err != nil {
+		return nil, err
+	}
+	for _, creator := range req.Creators {
+		if creator.Verified && creator.Address != feePayer.PublicKey {
+			return nil, fmt.Errorf("creator %v cannot be verified at mint, only the fee payer signs it", creator.Address.ToBase58())
+		}
+	}
+	if _, err := m.collectionMetadata(ctx, req.Collection, feePayer.PublicKey); err != nil {
+		return nil, err
+	}
+
+	treeAuthority, _, err := common.FindProgramAddress([][]byte{req.Tree.Bytes()}, BubblegumProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid tree config: %w", err)
+	}
+	config, err := m.getTreeConfig(ctx, treeAuthority)
+	if err != nil {
+		return nil, err
+	}
+	if !config.IsPublic && config.TreeCreator != feePayer.PublicKey && config.TreeDelegate != feePayer.PublicKey {
+		return nil, fmt.Errorf("%v may not mint into tree %v", feePayer.PublicKey.ToBase58(), req.Tree.ToBase58())
+	}
+	if config.NumMinted >= config.TotalMintCapacity {
+		return nil, fmt.Errorf("tree %v is full: %v of %v leaves minted", req.Tree.ToBase58(), config.NumMinted, config.TotalMintCapacity)
+	}
+	assetID, err := compressedAssetID(req.Tree, config.NumMinted)
+	if err != nil {
+		return nil, err
+	}
+
+	collectionMetadata, err := token_metadata.GetTokenMetaPubkey(req.Collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid collection metadata: %w", err)
+	}
+	collectionMasterEdition, err := token_metadata.GetMasterEdition(req.Collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid collection master edition: %w", err)
+	}
+	bubblegumSigner, _, err := common.FindProgramAddress([][]byte{[]byte("collection_cpi")}, BubblegumProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid bubblegum signer: %w", err)
+	}
+
+	creators := req.Creators
+	if creators == nil {
+		creators = []token_metadata.Creator{}
+	}
+	tokenStandard := token_metadata.NonFungible
+	args, err := borsh.Serialize(metadataArgs{
+		Name:                 name,
+		Symbol:               symbol,
+		Uri:                  req.URI,
+		SellerFeeBasisPoints: req.SellerFeeBasisPoints,
+		IsMutable:            req.Mutable,
+		TokenStandard:        &tokenStandard,
+		// Bubblegum marks the collection verified itself
+		Collection: &token_metadata.Collection{Verified: false, Key: req.Collection},
+		Creators:   creators,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize metadata args: %w", err)
+	}
+	discriminator := anchorDiscriminator("mint_to_collection_v1")
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: []types.Account{feePayer},
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions: []types.Instruction{
+				{
+					ProgramID: BubblegumProgramID,
+					Accounts: []types.AccountMeta{
+						{PubKey: treeAuthority, IsSigner: false, IsWritable: true},
+						{PubKey: req.Receiver, IsSigner: false, IsWritable: false}, // leaf owner
+						{PubKey: req.Receiver, IsSigner: false, IsWritable: false}, // leaf delegate
+						{PubKey: req.Tree, IsSigner: false, IsWritable: true},
+						{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: true},   // payer
+						{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false},  // tree delegate
+						{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false},  // collection authority
+						{PubKey: BubblegumProgramID, IsSigner: false, IsWritable: false}, // no collection authority record
+						{PubKey: req.Collection, IsSigner: false, IsWritable: false},
+						{PubKey: collectionMetadata, IsSigner: false, IsWritable: true},
+						{PubKey: collectionMasterEdition, IsSigner: false, IsWritable: false},
+						{PubKey: bubblegumSigner, IsSigner: false, IsWritable: false},
+						{PubKey: SPLNoopProgramID, IsSigner: false, IsWritable: false},
+						{PubKey: SPLAccountCompressionProgramID, IsSigner: false, IsWritable: false},
+						{PubKey: common.MetaplexTokenMetaProgramID, IsSigner: false, IsWritable: false},
+						{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+					},
+					Data: append(discriminator[:], args...),
+				},
+			},
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new a tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &CompressedMintResult{Signature: txSig, AssetID: assetID}, nil
+}
+
+func (m *Minter) getTreeConfig(ctx context.Context, treeAuthority common.PublicKey) (treeConfig, error) {
+	accountInfo, err := m.client.GetAccountInfoWithConfig(ctx, treeAuthority.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return treeConfig{}, fmt.Errorf("failed to get tree config: %w", err)
+	}
+	var config treeConfig
+	if accountInfo.Owner != BubblegumProgramID || borsh.Deserialize(&config, accountInfo.Data) != nil {
+		return treeConfig{}, fmt.Errorf("%v is not a Bubblegum tree config", treeAuthority.ToBase58())
+	}
+	return config, nil
+}
+
+// compressedAssetID derives the ID of the cNFT at leaf index of tree.
+func compressedAssetID(tree common.PublicKey, index uint64) (common.PublicKey, error) {
+	leaf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(leaf, index)
+	assetID, _, err := common.FindProgramAddress([][]byte{[]byte("asset"), tree.Bytes(), leaf}, BubblegumProgramID)
+	if err != nil {
+		return common.PublicKey{}, fmt.Errorf("failed to find a valid asset id: %w", err)
+	}
+	return assetID, nil
+}
+
+// anchorDiscriminator is the 8-byte prefix Anchor programs such as Bubblegum
+// expect before the arguments of instruction name.
+func anchorDiscriminator(name string) [8]byte {
+	var discriminator [8]byte
+	digest := sha256.Sum256([]byte("global:" + name))
+	copy(discriminator[:], digest[:8])
+	return discriminator
+}
+
+type CompressedTransferRequest struct {
+	AssetID common.PublicKey
+	// Owner is the current leaf owner and signs the transfer.
+	Owner    types.Account
+	Receiver common.PublicKey
+}
+
+// TransferCompressed moves a compressed NFT to the receiver with the
+// Bubblegum transfer instruction. The asset and its Merkle proof come from
+// the DAS API, so the leaf must not change between the read and the send.
+func (m *Minter) TransferCompressed(ctx context.Context, req CompressedTransferRequest) (string, error) {
+
+	feePayer := m.feePayer
+	if m.DAS == nil {
+		return "", ErrNoDAS
+	}
+
+	if err := m.Screening.Check(ctx, req.Owner.PublicKey, req.Receiver, req.AssetID); err != nil {
+		return "", err
+	}
+
+	asset, err := m.DAS.GetAsset(ctx, req.AssetID.ToBase58())
+	if err != nil {
+		return "", err
+	}
+	if !asset.Compression.Compressed {
+		return "", fmt.Errorf("%v is not a compressed NFT", req.AssetID.ToBase58())
+	}
+	if asset.Ownership.Owner != req.Owner.PublicKey.ToBase58() {
+		return "", fmt.Errorf("%v is owned by %v, not %v", req.AssetID.ToBase58(), asset.Ownership.Owner, req.Owner.PublicKey.ToBase58())
+	}
+	if asset.Ownership.Frozen {
+		return "", fmt.Errorf("%v is frozen", req.AssetID.ToBase58())
+	}
+	proof, err := m.DAS.GetAssetProof(ctx, req.AssetID.ToBase58())
+	if err != nil {
+		return "", err
+	}
+
+	tree, err := ParsePublicKey(asset.Compression.Tree)
+	if err != nil {
+		return "", fmt.Errorf("invalid tree of %v: %w", req.AssetID.ToBase58(), err)
+	}
+	delegate := req.Owner.PublicKey
+	if asset.Ownership.Delegated {
+		if delegate, err = ParsePublicKey(asset.Ownership.Delegate); err != nil {
+			return "", fmt.Errorf("invalid delegate of %v: %w", req.AssetID.ToBase58(), err)
+		}
+	}
+	treeAuthority, _, err := common.FindProgramAddress([][]byte{tree.Bytes()}, BubblegumProgramID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find a valid tree config: %w", err)
+	}
+
+	var args struct {
+		Root        [32]byte
+		DataHash    [32]byte
+		CreatorHash [32]byte
+		Nonce       uint64
+		Index       uint32
+	}
+	for _, hash := range []struct {
+		value string
+		into  *[32]byte
+	}{
+		{proof.Root, &args.Root},
+		{asset.Compression.DataHash, &args.DataHash},
+		{asset.Compression.CreatorHash, &args.CreatorHash},
+	} {
+		if *hash.into, err = decodeHash(hash.value); err != nil {
+			return "", fmt.Errorf("invalid hash in the asset or proof of %v: %w", req.AssetID.ToBase58(), err)
+		}
+	}
+	args.Nonce = asset.Compression.LeafID
+	args.Index = uint32(asset.Compression.LeafID)
+
+	// the tree caches the top of every proof in its canopy, only the rest
+	// is passed
+	canopyDepth, err := m.canopyDepth(ctx, tree)
+	if err != nil {
+		return "", err
+	}
+	if canopyDepth > len(proof.Proof) {
+		return "", fmt.Errorf("proof of %v is shorter than the canopy of tree %v", req.AssetID.ToBase58(), tree.ToBase58())
+	}
+
+	accounts := []types.AccountMeta{
+		{PubKey: treeAuthority, IsSigner: false, IsWritable: false},
+		{PubKey: req.Owner.PublicKey, IsSigner: true, IsWritable: false}, // leaf owner
+		{PubKey: delegate, IsSigner: false, IsWritable: false},           // leaf delegate
+		{PubKey: req.Receiver, IsSigner: false, IsWritable: false},
+		{PubKey: tree, IsSigner: false, IsWritable: true},
+		{PubKey: SPLNoopProgramID, IsSigner: false, IsWritable: false},
+		{PubKey: SPLAccountCompressionProgramID, IsSigner: false, IsWritable: false},
+		{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+	}
+	for _, node := range proof.Proof[:len(proof.Proof)-canopyDepth] {
+		key, err := ParsePublicKey(node)
+		if err != nil {
+			return "", fmt.Errorf("invalid proof node of %v: %w", req.AssetID.ToBase58(), err)
+		}
+		accounts = append(accounts, types.AccountMeta{PubKey: key, IsSigner: false, IsWritable: false})
+	}
+
+	data, err := borsh.Serialize(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize transfer args: %w", err)
+	}
+	discriminator := anchorDiscriminator("transfer")
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: []types.Account{feePayer, req.Owner},
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions: []types.Instruction{
+				{
+					ProgramID: BubblegumProgramID,
+					Accounts:  accounts,
+					Data:      append(discriminator[:], data...),
+				},
+			},
+		}),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to send tx: %w", err)
+	}
+	return txSig, nil
+}
+
+// concurrentMerkleTreeHeaderSize is the account discriminator, header
+// version and ConcurrentMerkleTreeHeaderDataV1 of spl-account-compression.
+const concurrentMerkleTreeHeaderSize = 2 + 54
+
+// merkleTreeSize is the size of a concurrent Merkle tree account: its
+// header, change log buffer, rightmost proof and canopy.
+func merkleTreeSize(maxDepth, maxBufferSize uint32, canopyDepth int) int {
+	changeLogSize := 32 + 32*int(maxDepth) + 4 + 4
+	rightmostProofSize := 32*int(maxDepth) + 32 + 4 + 4
+	// a canopy of depth d holds 2^(d+1) - 2 nodes
+	canopySize := 32 * (1<<(canopyDepth+1) - 2)
+	return concurrentMerkleTreeHeaderSize + 8 + 8 + 8 + int(maxBufferSize)*changeLogSize + rightmostProofSize + canopySize
+}
+
+// canopyDepth reads how many levels of the tree's top are cached on chain,
+// from the bytes left after its header, change log buffer and rightmost
+// proof.
+func (m *Minter) canopyDepth(ctx context.Context, tree common.PublicKey) (int, error) {
+	accountInfo, err := m.client.GetAccountInfoWithConfig(ctx, tree.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tree account: %w", err)
+	}
+	data := accountInfo.Data
+	if accountInfo.Owner != SPLAccountCompressionProgramID || len(data) < concurrentMerkleTreeHeaderSize {
+		return 0, fmt.Errorf("%v is not a concurrent Merkle tree", tree.ToBase58())
+	}
+
+	maxBufferSize := binary.LittleEndian.Uint32(data[2:6])
+	maxDepth := binary.LittleEndian.Uint32(data[6:10])
+	canopyBytes := len(data) - merkleTreeSize(maxDepth, maxBufferSize, 0)
+	if canopyBytes < 0 {
+		return 0, fmt.Errorf("%v is not a concurrent Merkle tree", tree.ToBase58())
+	}
+	nodes, depth := canopyBytes/32, 0
+	for 1<<(depth+2)-2 <= nodes {
+		depth++
+	}
+	return depth, nil
+}
+
+// decodeHash decodes a base58 32-byte hash as returned by the DAS API.
+func decodeHash(s string) ([32]byte, error) {
+	var hash [32]byte
+	decoded, err := base58.Decode(s)
+	if err != nil {
+		return hash, err
+	}
+	if len(decoded) != len(hash) {
+		return hash, fmt.Errorf("want 32 bytes, got %v", len(decoded))
+	}
+	copy(hash[:], decoded)
+	return hash, nil
+}
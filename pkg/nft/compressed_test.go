@@ -0,0 +1,26 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+func TestMintCompressedURITemplate(t *testing.T) {
+	// nothing listens here, so any RPC call fails with another error
+	m := NewMinter(client.NewClient("http://127.0.0.1:1"), types.NewAccount())
+
+	_, err := m.MintCompressed(context.Background(), CompressedMintRequest{
+		Tree:       testSource,
+		Receiver:   testOwner,
+		Name:       "Leaf",
+		URI:        "https://example.com/{mint}.json",
+		Collection: testMint,
+	})
+	if !errors.Is(err, ErrCompressedURITemplate) {
+		t.Errorf("got error %v, want %v", err, ErrCompressedURITemplate)
+	}
+}
@@ -0,0 +1,168 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+)
+
+var (
+	ErrTxFailed       = errors.New("transaction failed")
+	ErrConfirmTimeout = errors.New("transaction not confirmed in time")
+)
+
+// FinalizedTx is a transaction read back from a finalized block.
+type FinalizedTx struct {
+	Slot uint64
+	// BlockTime is nil when the node does not know the block's time.
+	BlockTime *time.Time
+	Fee       uint64
+}
+
+// Defaults of ConfirmOptions.
+const (
+	DefaultConfirmTimeout      = 90 * time.Second
+	DefaultConfirmPollInterval = 2 * time.Second
+)
+
+// ConfirmOptions tune WaitForConfirmation; zero fields take their defaults.
+type ConfirmOptions struct {
+	// Commitment to wait for, e.g. finalized; empty means the Minter's.
+	Commitment rpc.Commitment
+	// Timeout bounds the wait; zero means DefaultConfirmTimeout. A blockhash
+	// expires after about a minute, so a transaction not confirmed by then
+	// never will be.
+	Timeout time.Duration
+	// PollInterval is the time between status checks when polling; zero
+	// means DefaultConfirmPollInterval.
+	PollInterval time.Duration
+}
+
+// WaitForConfirmation waits until the transaction reaches the commitment of
+// opts. It subscribes to the signature on WSEndpoint when set, and
+// otherwise, or when the websocket fails, polls the signature status. A
+// transaction that failed on chain returns ErrTxFailed, and one still
+// unconfirmed after the timeout ErrConfirmTimeout.
+func (m *Minter) WaitForConfirmation(ctx context.Context, txHash string, opts ConfirmOptions) error {
+
+	commitment := opts.Commitment
+	if commitment == "" {
+		commitment = m.Commitment
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultConfirmTimeout
+	}
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultConfirmPollInterval
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	timedOut := func() error {
+		if parent.Err() != nil {
+			return parent.Err()
+		}
+		return fmt.Errorf("%w: %v after %v", ErrConfirmTimeout, txHash, timeout)
+	}
+
+	if m.WSEndpoint != "" {
+		err := m.waitForSignatureNotification(ctx, txHash, commitment)
+		if ctx.Err() != nil {
+			return timedOut()
+		}
+		if err == nil || errors.Is(err, ErrTxFailed) {
+			return err
+		}
+	}
+
+	for {
+		// RPC errors are retried on the next tick
+		reached, err := m.signatureStatusReached(ctx, txHash, commitment)
+		if reached || err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return timedOut()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// WaitFinalized waits for the transaction to be finalized, then reads it back
+// with getTransaction at finalized commitment, so callers only act on a
+// transaction present in a finalized block. opts.Commitment is ignored; its
+// Timeout bounds the whole wait. A transaction that failed on chain returns
+// ErrTxFailed, and one not finalized or not readable in time
+// ErrConfirmTimeout.
+func (m *Minter) WaitFinalized(ctx context.Context, txHash string, opts ConfirmOptions) (*FinalizedTx, error) {
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultConfirmTimeout
+	}
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultConfirmPollInterval
+	}
+	deadline := time.Now().Add(timeout)
+
+	opts.Commitment = rpc.CommitmentFinalized
+	if err := m.WaitForConfirmation(ctx, txHash, opts); err != nil {
+		return nil, err
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	for {
+		// the status may run ahead of the node's block store, in which case
+		// the lookup is retried
+		tx, err := m.client.GetTransactionWithConfig(ctx, txHash, client.GetTransactionConfig{Commitment: rpc.CommitmentFinalized})
+		if err == nil && tx != nil {
+			return finalizedTx(tx)
+		}
+
+		select {
+		case <-ctx.Done():
+			if parent.Err() != nil {
+				return nil, parent.Err()
+			}
+			return nil, fmt.Errorf("%w: %v not readable after %v", ErrConfirmTimeout, txHash, timeout)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func finalizedTx(tx *client.Transaction) (*FinalizedTx, error) {
+	finalized := &FinalizedTx{Slot: tx.Slot}
+	if tx.Meta != nil {
+		if tx.Meta.Err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTxFailed, tx.Meta.Err)
+		}
+		finalized.Fee = tx.Meta.Fee
+	}
+	if tx.BlockTime != nil {
+		blockTime := time.Unix(*tx.BlockTime, 0).UTC()
+		finalized.BlockTime = &blockTime
+	}
+	return finalized, nil
+}
+
+// commitmentReached reports whether status is at least as final as target.
+func commitmentReached(status, target rpc.Commitment) bool {
+	rank := map[rpc.Commitment]int{
+		rpc.CommitmentProcessed: 0,
+		rpc.CommitmentConfirmed: 1,
+		rpc.CommitmentFinalized: 2,
+	}
+	return rank[status] >= rank[target]
+}
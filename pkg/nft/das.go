@@ -0,0 +1,190 @@
+package nft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+var ErrNoDAS = errors.New("no DAS endpoint configured")
+
+// ErrDASUnsupported is returned when the endpoint is a plain RPC node that
+// does not serve the DAS methods.
+var ErrDASUnsupported = errors.New("endpoint does not serve the DAS API")
+
+// dasPageLimit is the most assets a DAS page holds.
+const dasPageLimit = 1000
+
+// dasTimeout bounds one DAS request, a page of dasPageLimit assets included.
+const dasTimeout = 30 * time.Second
+
+// jsonRPCMethodNotFound is the JSON-RPC error code of an unknown method.
+const jsonRPCMethodNotFound = -32601
+
+// DASClient queries the Digital Asset Standard API that RPC providers such
+// as Helius and Triton serve next to the Solana JSON-RPC methods.
+type DASClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewDASClient returns a DASClient posting to endpoint, usually the RPC URL.
+func NewDASClient(endpoint string) *DASClient {
+	return &DASClient{endpoint: endpoint, httpClient: &http.Client{Timeout: dasTimeout}}
+}
+
+// Asset is the subset of a DAS asset this package reads.
+type Asset struct {
+	ID          string           `json:"id"`
+	Interface   string           `json:"interface"`
+	Content     AssetContent     `json:"content"`
+	Compression AssetCompression `json:"compression"`
+	Ownership   AssetOwnership   `json:"ownership"`
+	Mutable     bool             `json:"mutable"`
+	Burnt       bool             `json:"burnt"`
+	// TokenInfo is only served by some providers, e.g. Helius.
+	TokenInfo *AssetTokenInfo `json:"token_info"`
+}
+
+type AssetContent struct {
+	JSONURI  string `json:"json_uri"`
+	Metadata struct {
+		Name   string `json:"name"`
+		Symbol string `json:"symbol"`
+	} `json:"metadata"`
+}
+
+type AssetCompression struct {
+	Compressed  bool   `json:"compressed"`
+	Tree        string `json:"tree"`
+	LeafID      uint64 `json:"leaf_id"`
+	DataHash    string `json:"data_hash"`
+	CreatorHash string `json:"creator_hash"`
+}
+
+type AssetOwnership struct {
+	Owner     string `json:"owner"`
+	Delegate  string `json:"delegate"`
+	Delegated bool   `json:"delegated"`
+	Frozen    bool   `json:"frozen"`
+}
+
+type AssetTokenInfo struct {
+	Supply       uint64 `json:"supply"`
+	Decimals     uint8  `json:"decimals"`
+	TokenProgram string `json:"token_program"`
+}
+
+// AssetList is one page of assets.
+type AssetList struct {
+	Total int     `json:"total"`
+	Limit int     `json:"limit"`
+	Page  int     `json:"page"`
+	Items []Asset `json:"items"`
+}
+
+// AssetProof is the Merkle proof of a compressed asset's leaf, ordered from
+// the leaf up to the root.
+type AssetProof struct {
+	Root   string   `json:"root"`
+	Proof  []string `json:"proof"`
+	Leaf   string   `json:"leaf"`
+	TreeID string   `json:"tree_id"`
+}
+
+func (c *DASClient) GetAsset(ctx context.Context, id string) (*Asset, error) {
+	var asset Asset
+	if err := c.call(ctx, "getAsset", map[string]any{"id": id}, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// GetAssetsByOwner returns page, counted from 1, of the assets owner holds,
+// compressed or not, at most limit of them.
+func (c *DASClient) GetAssetsByOwner(ctx context.Context, owner string, page, limit int) (*AssetList, error) {
+	var list AssetList
+	if err := c.call(ctx, "getAssetsByOwner", map[string]any{"ownerAddress": owner, "page": page, "limit": limit}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetAssetsByGroup returns page, counted from 1, of the assets grouped under
+// groupValue by groupKey, e.g. the members of a verified "collection".
+func (c *DASClient) GetAssetsByGroup(ctx context.Context, groupKey, groupValue string, page, limit int) (*AssetList, error) {
+	var list AssetList
+	if err := c.call(ctx, "getAssetsByGroup", map[string]any{"groupKey": groupKey, "groupValue": groupValue, "page": page, "limit": limit}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *DASClient) GetAssetProof(ctx context.Context, id string) (*AssetProof, error) {
+	var proof AssetProof
+	if err := c.call(ctx, "getAssetProof", map[string]any{"id": id}, &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// call posts a JSON-RPC request with named params, as the DAS methods take
+// them, and decodes its result.
+func (c *DASClient) call(ctx context.Context, method string, params any, result any) error {
+
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %v request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build %v request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %v: %w", method, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %v response: %w", method, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v returned status %v: %s", method, res.StatusCode, data)
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to decode %v response: %w", method, err)
+	}
+	if response.Error != nil && response.Error.Code == jsonRPCMethodNotFound {
+		return fmt.Errorf("%v: %w", method, ErrDASUnsupported)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%v failed: %v (code %v)", method, response.Error.Message, response.Error.Code)
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %v result: %w", method, err)
+	}
+	return nil
+}
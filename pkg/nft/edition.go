@@ -0,0 +1,162 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/associated_token_account"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/near/borsh-go"
+)
+
+var ErrEditionsSoldOut = errors.New("every edition of the master has been printed")
+
+type PrintRequest struct {
+	// Master is the mint of an NFT minted with MaxEditions or
+	// UnlimitedEditions.
+	Master common.PublicKey
+	// MasterHolder holds the master token and signs the print; the zero
+	// account means the fee payer.
+	MasterHolder types.Account
+	Receiver     common.PublicKey
+}
+
+type PrintResult struct {
+	Signature    string
+	Mint         common.PublicKey
+	TokenAccount common.PublicKey
+	// Edition is the print's number, starting at 1.
+	Edition uint64
+}
+
+// PrintEdition mints the next numbered edition of a master edition NFT to the
+// receiver's associated token account.
+func (m *Minter) PrintEdition(ctx context.Context, req PrintRequest) (*PrintResult, error) {
+
+	feePayer := m.feePayer
+	holder := req.MasterHolder
+	if holder.PublicKey == (common.PublicKey{}) {
+		holder = feePayer
+	}
+
+	masterMetadata, err := token_metadata.GetTokenMetaPubkey(req.Master)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid master metadata: %w", err)
+	}
+	masterEditionPubkey, err := token_metadata.GetMasterEdition(req.Master)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid master edition: %w", err)
+	}
+	masterTokenAccount, _, err := common.FindAssociatedTokenAddress(holder.PublicKey, req.Master)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the master holder's ATA: %w", err)
+	}
+
+	// master edition info
+	masterEditionInfo, err := m.client.GetAccountInfoWithConfig(ctx, masterEditionPubkey.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get master edition info: %w", err)
+	}
+	var masterEdition token_metadata.MasterEditionV2
+	if err := borsh.Deserialize(&masterEdition, masterEditionInfo.Data); err != nil || masterEdition.Key != token_metadata.KeyMasterEditionV2 {
+		return nil, fmt.Errorf("%v has no master edition", req.Master.ToBase58())
+	}
+	if masterEdition.MaxSupply != nil && masterEdition.Supply >= *masterEdition.MaxSupply {
+		return nil, fmt.Errorf("%w: %v of %v", ErrEditionsSoldOut, masterEdition.Supply, *masterEdition.MaxSupply)
+	}
+	edition := masterEdition.Supply + 1
+
+	mint := types.NewAccount()
+
+	ata, _, err := common.FindAssociatedTokenAddress(req.Receiver, mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid ata: %w", err)
+	}
+	newMetadata, err := token_metadata.GetTokenMetaPubkey(mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	newEdition, err := token_metadata.GetMasterEdition(mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid edition: %w", err)
+	}
+	editionMark, err := token_metadata.GetEditionMark(req.Master, edition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid edition mark: %w", err)
+	}
+
+	mintAccountRent, err := m.client.GetMinimumBalanceForRentExemption(ctx, token.MintAccountSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint account rent: %w", err)
+	}
+
+	recentBlockhashResponse, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: []types.Account{mint, feePayer, holder},
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: recentBlockhashResponse.Blockhash,
+			Instructions: []types.Instruction{
+				system.CreateAccount(system.CreateAccountParam{
+					From:     feePayer.PublicKey,
+					New:      mint.PublicKey,
+					Owner:    common.TokenProgramID,
+					Lamports: mintAccountRent,
+					Space:    token.MintAccountSize,
+				}),
+				token.InitializeMint(token.InitializeMintParam{
+					Decimals:   0,
+					Mint:       mint.PublicKey,
+					MintAuth:   feePayer.PublicKey,
+					FreezeAuth: &feePayer.PublicKey,
+				}),
+				associated_token_account.CreateIdempotent(associated_token_account.CreateIdempotentParam{
+					Funder:                 feePayer.PublicKey,
+					Owner:                  req.Receiver,
+					Mint:                   mint.PublicKey,
+					AssociatedTokenAccount: ata,
+				}),
+				token.MintTo(token.MintToParam{
+					Mint:   mint.PublicKey,
+					To:     ata,
+					Auth:   feePayer.PublicKey,
+					Amount: 1,
+				}),
+				token_metadata.MintNewEditionFromMasterEditionViaToken(token_metadata.MintNewEditionFromMasterEditionViaTokeParam{
+					NewMetaData:                newMetadata,
+					NewEdition:                 newEdition,
+					MasterEdition:              masterEditionPubkey,
+					NewMint:                    mint.PublicKey,
+					EditionMark:                editionMark,
+					NewMintAuthority:           feePayer.PublicKey,
+					Payer:                      feePayer.PublicKey,
+					TokenAccountOwner:          holder.PublicKey,
+					TokenAccount:               masterTokenAccount,
+					NewMetadataUpdateAuthority: feePayer.PublicKey,
+					MasterMetadata:             masterMetadata,
+					Edition:                    edition,
+				}),
+			},
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new a tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &PrintResult{Signature: txSig, Mint: mint.PublicKey, TokenAccount: ata, Edition: edition}, nil
+}
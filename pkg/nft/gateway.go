@@ -0,0 +1,21 @@
+package nft
+
+import "strings"
+
+// Gateways rewriting ipfs:// and ar:// URIs to HTTP.
+var (
+	IPFSGateway    = "https://ipfs.io/ipfs/"
+	ArweaveGateway = "https://arweave.net/"
+)
+
+// GatewayURL returns the HTTP URL serving uri.
+func GatewayURL(uri string) string {
+	switch {
+	case strings.HasPrefix(uri, "ipfs://"):
+		return IPFSGateway + strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/")
+	case strings.HasPrefix(uri, "ar://"):
+		return ArweaveGateway + strings.TrimPrefix(uri, "ar://")
+	default:
+		return uri
+	}
+}
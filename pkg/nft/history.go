@@ -0,0 +1,269 @@
+package nft
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+var ErrNoHistory = errors.New("no metadata history configured")
+
+// uriFetchTimeout bounds fetching a metadata URI to hash its content.
+const uriFetchTimeout = 10 * time.Second
+
+// MetadataVersion records one metadata update sent by UpdateNFT.
+type MetadataVersion struct {
+	Mint      string           `json:"mint"`
+	Signature string           `json:"signature"`
+	Time      time.Time        `json:"time"`
+	Old       MetadataSnapshot `json:"old"`
+	New       MetadataSnapshot `json:"new"`
+}
+
+// MetadataSnapshot is the DataV2 of an NFT at one point of its history.
+type MetadataSnapshot struct {
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	URI    string `json:"uri"`
+	// URISHA256 is the digest of the content served at URI when the
+	// snapshot was recorded, empty if it could not be fetched.
+	URISHA256 string `json:"uriSha256,omitempty"`
+	// URIHashError says why URISHA256 is empty.
+	URIHashError         string            `json:"uriHashError,omitempty"`
+	SellerFeeBasisPoints uint16            `json:"sellerFeeBasisPoints"`
+	Creators             []SnapshotCreator `json:"creators,omitempty"`
+	Collection           string            `json:"collection,omitempty"`
+}
+
+type SnapshotCreator struct {
+	Address  string `json:"address"`
+	Share    uint8  `json:"share"`
+	Verified bool   `json:"verified"`
+}
+
+func newSnapshot(data token_metadata.DataV2) MetadataSnapshot {
+	snapshot := MetadataSnapshot{
+		Name:                 data.Name,
+		Symbol:               data.Symbol,
+		URI:                  data.Uri,
+		SellerFeeBasisPoints: data.SellerFeeBasisPoints,
+	}
+	if data.Creators != nil {
+		for _, creator := range *data.Creators {
+			snapshot.Creators = append(snapshot.Creators, SnapshotCreator{
+				Address:  creator.Address.ToBase58(),
+				Share:    creator.Share,
+				Verified: creator.Verified,
+			})
+		}
+	}
+	if data.Collection != nil {
+		snapshot.Collection = data.Collection.Key.ToBase58()
+	}
+	return snapshot
+}
+
+// updateRequest builds the update that restores the snapshot on mint.
+func (s MetadataSnapshot) updateRequest(mint common.PublicKey) (UpdateRequest, error) {
+	creators := []token_metadata.Creator{}
+	for _, creator := range s.Creators {
+		address, err := ParsePublicKey(creator.Address)
+		if err != nil {
+			return UpdateRequest{}, fmt.Errorf("invalid creator in snapshot: %w", err)
+		}
+		creators = append(creators, token_metadata.Creator{Address: address, Verified: creator.Verified, Share: creator.Share})
+	}
+	collection := common.PublicKey{}
+	if s.Collection != "" {
+		var err error
+		if collection, err = ParsePublicKey(s.Collection); err != nil {
+			return UpdateRequest{}, fmt.Errorf("invalid collection in snapshot: %w", err)
+		}
+	}
+	return UpdateRequest{
+		Mint:                 mint,
+		Name:                 &s.Name,
+		URI:                  &s.URI,
+		Creators:             &creators,
+		SellerFeeBasisPoints: &s.SellerFeeBasisPoints,
+		Collection:           &collection,
+	}, nil
+}
+
+// MetadataHistory keeps MetadataVersions in a JSON lines file, one line per
+// update, oldest first.
+type MetadataHistory struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewMetadataHistory(path string) *MetadataHistory {
+	return &MetadataHistory{path: path}
+}
+
+func (h *MetadataHistory) Record(version MetadataVersion) error {
+	line, err := json.Marshal(version)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata version: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return f.Close()
+}
+
+// Versions returns the recorded updates of mint, oldest first.
+func (h *MetadataHistory) Versions(mint common.PublicKey) ([]MetadataVersion, error) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var versions []MetadataVersion
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		var version MetadataVersion
+		if err := json.Unmarshal(scanner.Bytes(), &version); err != nil {
+			return nil, fmt.Errorf("history line %v: %w", n, err)
+		}
+		if version.Mint == mint.ToBase58() {
+			versions = append(versions, version)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return versions, nil
+}
+
+// recordUpdate hashes the content behind both URIs and appends the update
+// to the history.
+func (m *Minter) recordUpdate(ctx context.Context, mint common.PublicKey, txSig string, old, updated MetadataSnapshot) error {
+	old.hashURI(ctx)
+	updated.hashURI(ctx)
+	return m.History.Record(MetadataVersion{
+		Mint:      mint.ToBase58(),
+		Signature: txSig,
+		Time:      time.Now().UTC(),
+		Old:       old,
+		New:       updated,
+	})
+}
+
+type RollbackResult struct {
+	Signature string
+	// Restored is the snapshot written back.
+	Restored MetadataSnapshot
+	// URIChanged reports that the content at the restored URI no longer
+	// matches the digest recorded with the snapshot.
+	URIChanged bool
+}
+
+// Rollback restores the metadata of mint as it was at version, 0 being the
+// state before the first recorded update and n the state after the n-th.
+// The rollback is an update itself and is recorded as the next version.
+func (m *Minter) Rollback(ctx context.Context, mint common.PublicKey, version int, authority types.Account) (*RollbackResult, error) {
+	if m.History == nil {
+		return nil, ErrNoHistory
+	}
+	versions, err := m.History.Versions(mint)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no recorded updates of %v", mint.ToBase58())
+	}
+	if version < 0 || version > len(versions) {
+		return nil, fmt.Errorf("%v has versions 0 to %v, not %v", mint.ToBase58(), len(versions), version)
+	}
+
+	target := versions[0].Old
+	if version > 0 {
+		target = versions[version-1].New
+	}
+	req, err := target.updateRequest(mint)
+	if err != nil {
+		return nil, err
+	}
+	req.UpdateAuthority = authority
+
+	result := &RollbackResult{Restored: target}
+	if target.URISHA256 != "" {
+		digest, err := HashURI(ctx, target.URI)
+		result.URIChanged = err == nil && digest != target.URISHA256
+	}
+
+	result.Signature, err = m.UpdateNFT(ctx, req)
+	if result.Signature == "" {
+		return nil, err
+	}
+	return result, err
+}
+
+// hashURI records the digest of the content at s.URI, or why it could not be
+// taken.
+func (s *MetadataSnapshot) hashURI(ctx context.Context) {
+	digest, err := HashURI(ctx, s.URI)
+	if err != nil {
+		s.URIHashError = err.Error()
+		return
+	}
+	s.URISHA256 = digest
+}
+
+// HashURI fetches uri, through a gateway for ipfs:// and ar:// URIs, and
+// returns the hex SHA-256 of its content.
+func HashURI(ctx context.Context, uri string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, uriFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, GatewayURL(uri), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request for %v: %w", uri, err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %v: %w", uri, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch %v: status %v", uri, res.StatusCode)
+	}
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, res.Body); err != nil {
+		return "", fmt.Errorf("failed to read %v: %w", uri, err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
@@ -0,0 +1,118 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/token"
+)
+
+// NFTInfo is the on-chain state of an NFT as seen from one token account.
+type NFTInfo struct {
+	TokenAccountAddress common.PublicKey
+	TokenAccount        token.TokenAccount
+	MintAddress         common.PublicKey
+	Mint                token.MintAccount
+	// TokenProgram is the classic token program or Token-2022.
+	TokenProgram common.PublicKey
+	// Extensions is set for Token-2022 mints that carry extensions.
+	Extensions *MintExtensions
+	// Metadata is the Metaplex metadata account; nil when a Token-2022 mint
+	// keeps its metadata in Extensions.TokenMetadata instead.
+	Metadata *token_metadata.Metadata
+}
+
+// GetInfo reads the token account, its mint and the mint's metadata.
+func (m *Minter) GetInfo(ctx context.Context, tokenAccountAddress common.PublicKey) (*NFTInfo, error) {
+
+	//token account info
+	getAccountInfoResponse, err := m.client.GetAccountInfoWithConfig(ctx, tokenAccountAddress.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token account info: %w", err)
+	}
+
+	tokenAccountData := getAccountInfoResponse.Data
+	if getAccountInfoResponse.Owner == common.Token2022ProgramID {
+		tokenAccountData, _, err = splitToken2022Data(tokenAccountData, token.TokenAccountSize, token2022AccountTypeAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split token-2022 account data: %w", err)
+		}
+	}
+
+	tokenAccount, err := token.TokenAccountFromData(tokenAccountData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data to a token account: %w", err)
+	}
+
+	info := &NFTInfo{
+		TokenAccountAddress: tokenAccountAddress,
+		TokenAccount:        tokenAccount,
+		MintAddress:         tokenAccount.Mint,
+	}
+
+	// mint and metadata accounts, read together
+	metadataAccount, err := token_metadata.GetTokenMetaPubkey(info.MintAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata account: %w", err)
+	}
+	accounts, err := m.getMultipleAccounts(ctx, []common.PublicKey{info.MintAddress, metadataAccount})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint and metadata account info: %w", err)
+	}
+	mintAccountInfo, accountInfo := accounts[0], accounts[1]
+	info.TokenProgram = mintAccountInfo.Owner
+
+	mintData := mintAccountInfo.Data
+	var extensionData []byte
+	if mintAccountInfo.Owner == common.Token2022ProgramID {
+		mintData, extensionData, err = splitToken2022Data(mintData, token.MintAccountSize, token2022AccountTypeMint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split token-2022 mint data: %w", err)
+		}
+	}
+
+	info.Mint, err = token.MintAccountFromData(mintData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data to a mint account: %w", err)
+	}
+
+	if extensionData != nil {
+		extensions, err := parseMintExtensions(extensionData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token-2022 extensions: %w", err)
+		}
+		info.Extensions = &extensions
+	}
+
+	// token-2022 mints may keep their metadata in the mint itself instead
+	if len(accountInfo.Data) == 0 && info.Extensions != nil && info.Extensions.TokenMetadata != nil {
+		return info, nil
+	}
+
+	// parse it
+	metadata, err := token_metadata.MetadataDeserialize(accountInfo.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata account: %w", err)
+	}
+	info.Metadata = &metadata
+
+	return info, nil
+}
+
+// URI is the off-chain metadata URI of the NFT, from whichever metadata it
+// has.
+func (info *NFTInfo) URI() string {
+	switch {
+	case info.Metadata != nil:
+		// on-chain strings are padded with zero bytes to their maximum length
+		return strings.TrimRight(info.Metadata.Data.Uri, "\x00")
+	case info.Extensions != nil && info.Extensions.TokenMetadata != nil:
+		return info.Extensions.TokenMetadata.Uri
+	default:
+		return ""
+	}
+}
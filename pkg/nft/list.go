@@ -0,0 +1,257 @@
+package nft
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/rpc"
+)
+
+// multipleAccountsLimit is the most accounts one getMultipleAccounts call
+// returns.
+const multipleAccountsLimit = 100
+
+// dasNFTInterfaces are the DAS asset interfaces of NFTs.
+var dasNFTInterfaces = map[string]bool{
+	"V1_NFT":          true,
+	"V2_NFT":          true,
+	"LEGACY_NFT":      true,
+	"ProgrammableNFT": true,
+}
+
+// OwnedNFT is an NFT held by a wallet.
+type OwnedNFT struct {
+	// TokenAccount and TokenProgram are zero for compressed NFTs, whose Mint
+	// is their asset ID.
+	TokenAccount common.PublicKey
+	Mint         common.PublicKey
+	TokenProgram common.PublicKey
+	Compressed   bool
+	// Name, Symbol and URI come from the Metaplex metadata or the Token-2022
+	// metadata extension, and are empty when the mint has neither.
+	Name   string
+	Symbol string
+	URI    string
+	// Metadata is the Metaplex metadata account, nil when there is none or
+	// the NFT was listed through DAS.
+	Metadata *token_metadata.Metadata
+}
+
+// ListNFTs returns the NFTs owner holds. When the DAS endpoint serves the
+// DAS API they are read from it page by page, compressed NFTs included;
+// otherwise they are the owner's token accounts of either token program
+// holding one token of a zero-decimals mint.
+func (m *Minter) ListNFTs(ctx context.Context, owner common.PublicKey) ([]OwnedNFT, error) {
+	if m.DAS != nil {
+		nfts, err := m.listNFTsDAS(ctx, owner)
+		if !errors.Is(err, ErrDASUnsupported) {
+			return nfts, err
+		}
+	}
+	return m.listNFTsRPC(ctx, owner)
+}
+
+func (m *Minter) listNFTsDAS(ctx context.Context, owner common.PublicKey) ([]OwnedNFT, error) {
+
+	var nfts []OwnedNFT
+	for page := 1; ; page++ {
+		list, err := m.DAS.GetAssetsByOwner(ctx, owner.ToBase58(), page, dasPageLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range list.Items {
+			if !dasNFTInterfaces[asset.Interface] || asset.Burnt {
+				continue
+			}
+			nft, err := ownedNFTFromAsset(owner, asset)
+			if err != nil {
+				return nil, err
+			}
+			nfts = append(nfts, nft)
+		}
+		if len(list.Items) < dasPageLimit {
+			return nfts, nil
+		}
+	}
+}
+
+// ownedNFTFromAsset converts a DAS asset held by owner; the token account of
+// an uncompressed one is taken to be the owner's associated token account.
+func ownedNFTFromAsset(owner common.PublicKey, asset Asset) (OwnedNFT, error) {
+	id, err := ParsePublicKey(asset.ID)
+	if err != nil {
+		return OwnedNFT{}, fmt.Errorf("unexpected DAS asset: %w", err)
+	}
+	nft := OwnedNFT{
+		Mint:       id,
+		Compressed: asset.Compression.Compressed,
+		Name:       asset.Content.Metadata.Name,
+		Symbol:     asset.Content.Metadata.Symbol,
+		URI:        asset.Content.JSONURI,
+	}
+	if nft.Compressed {
+		return nft, nil
+	}
+
+	nft.TokenProgram = common.TokenProgramID
+	if asset.TokenInfo != nil && asset.TokenInfo.TokenProgram == common.Token2022ProgramID.ToBase58() {
+		nft.TokenProgram = common.Token2022ProgramID
+	}
+	if nft.TokenAccount, err = associatedTokenAddress(owner, id, nft.TokenProgram); err != nil {
+		return OwnedNFT{}, fmt.Errorf("failed to get associated token account: %w", err)
+	}
+	return nft, nil
+}
+
+func (m *Minter) listNFTsRPC(ctx context.Context, owner common.PublicKey) ([]OwnedNFT, error) {
+
+	var nfts []OwnedNFT
+	for _, tokenProgram := range []common.PublicKey{common.TokenProgramID, common.Token2022ProgramID} {
+		held, err := m.heldTokens(ctx, owner, tokenProgram)
+		if err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, held...)
+	}
+
+	// keep zero-decimals mints, taking the metadata of Token-2022 ones
+	mints := make([]common.PublicKey, len(nfts))
+	for i, nft := range nfts {
+		mints[i] = nft.Mint
+	}
+	mintInfos, err := m.getMultipleAccounts(ctx, mints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mints: %w", err)
+	}
+	kept := nfts[:0]
+	for i, nft := range nfts {
+		mintData := mintInfos[i].Data
+		var extensionData []byte
+		if nft.TokenProgram == common.Token2022ProgramID {
+			if mintData, extensionData, err = splitToken2022Data(mintData, token.MintAccountSize, token2022AccountTypeMint); err != nil {
+				continue
+			}
+		}
+		mint, err := token.MintAccountFromData(mintData)
+		if err != nil || mint.Decimals != 0 {
+			continue
+		}
+		if extensionData != nil {
+			if extensions, err := parseMintExtensions(extensionData); err == nil && extensions.TokenMetadata != nil {
+				nft.Name, nft.Symbol, nft.URI = extensions.TokenMetadata.Name, extensions.TokenMetadata.Symbol, extensions.TokenMetadata.Uri
+			}
+		}
+		kept = append(kept, nft)
+	}
+	nfts = kept
+
+	metadataAccounts := make([]common.PublicKey, len(nfts))
+	for i, nft := range nfts {
+		if metadataAccounts[i], err = token_metadata.GetTokenMetaPubkey(nft.Mint); err != nil {
+			return nil, fmt.Errorf("failed to get metadata account: %w", err)
+		}
+	}
+	metadataInfos, err := m.getMultipleAccounts(ctx, metadataAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata accounts: %w", err)
+	}
+	for i := range nfts {
+		if len(metadataInfos[i].Data) == 0 {
+			continue
+		}
+		metadata, err := token_metadata.MetadataDeserialize(metadataInfos[i].Data)
+		if err != nil {
+			continue
+		}
+		nfts[i].Metadata = &metadata
+		// on-chain strings are padded with zero bytes to their maximum length
+		nfts[i].Name = strings.TrimRight(metadata.Data.Name, "\x00")
+		nfts[i].Symbol = strings.TrimRight(metadata.Data.Symbol, "\x00")
+		nfts[i].URI = strings.TrimRight(metadata.Data.Uri, "\x00")
+	}
+
+	return nfts, nil
+}
+
+// heldTokens returns the token accounts of tokenProgram owned by owner that
+// hold exactly one token.
+func (m *Minter) heldTokens(ctx context.Context, owner, tokenProgram common.PublicKey) ([]OwnedNFT, error) {
+
+	// the client only decodes classic token accounts, so decode them here
+	res, err := m.client.RpcClient.GetTokenAccountsByOwnerWithConfig(ctx, owner.ToBase58(),
+		rpc.GetTokenAccountsByOwnerConfigFilter{ProgramId: tokenProgram.ToBase58()},
+		rpc.GetTokenAccountsByOwnerConfig{Commitment: m.Commitment, Encoding: rpc.AccountEncodingBase64},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token accounts: %w", err)
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to get token accounts: %v", res.Error.Message)
+	}
+
+	var held []OwnedNFT
+	for _, account := range res.Result.Value {
+		data, err := decodeAccountData(account.Account.Data)
+		if err != nil {
+			return nil, fmt.Errorf("token account %v: %w", account.Pubkey, err)
+		}
+		if tokenProgram == common.Token2022ProgramID {
+			if data, _, err = splitToken2022Data(data, token.TokenAccountSize, token2022AccountTypeAccount); err != nil {
+				return nil, fmt.Errorf("token account %v: %w", account.Pubkey, err)
+			}
+		}
+		tokenAccount, err := token.TokenAccountFromData(data)
+		if err != nil {
+			return nil, fmt.Errorf("token account %v: %w", account.Pubkey, err)
+		}
+		if tokenAccount.Amount != 1 {
+			continue
+		}
+		held = append(held, OwnedNFT{
+			TokenAccount: common.PublicKeyFromString(account.Pubkey),
+			Mint:         tokenAccount.Mint,
+			TokenProgram: tokenProgram,
+		})
+	}
+	return held, nil
+}
+
+// getMultipleAccounts reads accounts in as many calls as needed, in order;
+// missing accounts have no data.
+func (m *Minter) getMultipleAccounts(ctx context.Context, accounts []common.PublicKey) ([]client.AccountInfo, error) {
+	infos := make([]client.AccountInfo, 0, len(accounts))
+	for start := 0; start < len(accounts); start += multipleAccountsLimit {
+		end := min(start+multipleAccountsLimit, len(accounts))
+		addresses := make([]string, 0, end-start)
+		for _, account := range accounts[start:end] {
+			addresses = append(addresses, account.ToBase58())
+		}
+		batch, err := m.client.GetMultipleAccountsWithConfig(ctx, addresses, client.GetMultipleAccountsConfig{Commitment: m.Commitment})
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, batch...)
+	}
+	return infos, nil
+}
+
+// decodeAccountData decodes the ["<data>", "base64"] data of a raw RPC
+// account.
+func decodeAccountData(data any) ([]byte, error) {
+	encoded, ok := data.([]any)
+	if !ok || len(encoded) != 2 || encoded[1] != string(rpc.AccountEncodingBase64) {
+		return nil, errors.New("unexpected account data encoding")
+	}
+	s, ok := encoded[0].(string)
+	if !ok {
+		return nil, errors.New("unexpected account data encoding")
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
@@ -0,0 +1,149 @@
+package nft
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// encryptedMemoPrefix marks memos produced by EncryptMemo.
+const encryptedMemoPrefix = "nacl:"
+
+// MaxTransactionSize is the largest serialized transaction a validator
+// accepts, the size of a network packet.
+const MaxTransactionSize = 1232
+
+var (
+	ErrNotEncryptedMemo = errors.New("memo is not an encrypted memo")
+	ErrMemoTooLarge     = errors.New("encrypted memo does not fit in the transaction")
+)
+
+// EncryptMemo seals plaintext to the x25519 key derived from the receiver's
+// wallet address, using a one-off sender key so only the receiver can open
+// it. The result is "nacl:" + base64(ephemeral pubkey | nonce | box).
+func EncryptMemo(receiver common.PublicKey, plaintext []byte) (string, error) {
+	receiverKey, err := x25519PublicKey(receiver)
+	if err != nil {
+		return "", err
+	}
+
+	ephemeralPub, ephemeralPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return "", err
+	}
+
+	payload := append(ephemeralPub[:], nonce[:]...)
+	payload = box.Seal(payload, plaintext, &nonce, receiverKey, ephemeralPriv)
+
+	return encryptedMemoPrefix + base64.StdEncoding.EncodeToString(payload), nil
+}
+
+// DecryptMemo opens a memo produced by EncryptMemo with the receiver's keypair.
+func DecryptMemo(receiver types.Account, memo string) ([]byte, error) {
+	if len(memo) < len(encryptedMemoPrefix) || memo[:len(encryptedMemoPrefix)] != encryptedMemoPrefix {
+		return nil, ErrNotEncryptedMemo
+	}
+	payload, err := base64.StdEncoding.DecodeString(memo[len(encryptedMemoPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode memo: %w", err)
+	}
+	if len(payload) < 32+24+box.Overhead {
+		return nil, errors.New("encrypted memo is too short")
+	}
+
+	var ephemeralPub [32]byte
+	var nonce [24]byte
+	copy(ephemeralPub[:], payload[:32])
+	copy(nonce[:], payload[32:56])
+
+	plaintext, ok := box.Open(nil, payload[56:], &nonce, &ephemeralPub, x25519PrivateKey(receiver))
+	if !ok {
+		return nil, errors.New("failed to decrypt memo")
+	}
+	return plaintext, nil
+}
+
+// GetMemos returns the memos attached to the transaction txHash, encrypted
+// or not, in instruction order.
+func (m *Minter) GetMemos(ctx context.Context, txHash string) ([]string, error) {
+
+	// processed is not served by getTransaction
+	commitment := m.Commitment
+	if commitment == rpc.CommitmentProcessed {
+		commitment = rpc.CommitmentConfirmed
+	}
+	tx, err := m.client.GetTransactionWithConfig(ctx, txHash, client.GetTransactionConfig{Commitment: commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction %v: %w", txHash, err)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %v not found", txHash)
+	}
+
+	var memos []string
+	for _, instruction := range tx.Transaction.Message.Instructions {
+		if instruction.ProgramIDIndex < len(tx.AccountKeys) && tx.AccountKeys[instruction.ProgramIDIndex] == common.MemoProgramID {
+			memos = append(memos, string(instruction.Data))
+		}
+	}
+	return memos, nil
+}
+
+// EncryptedMemoSize is the length of the memo EncryptMemo makes of n bytes:
+// the prefix and base64 of the ephemeral key, nonce, box overhead and text.
+func EncryptedMemoSize(n int) int {
+	return len(encryptedMemoPrefix) + base64.StdEncoding.EncodedLen(32+24+box.Overhead+n)
+}
+
+// checkTransactionSize fails a transaction carrying a memo that serializes
+// over MaxTransactionSize, which the cluster would drop.
+func checkTransactionSize(tx types.Transaction, memoLen int) error {
+	data, err := tx.Serialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize tx: %w", err)
+	}
+	if len(data) > MaxTransactionSize {
+		return fmt.Errorf("%w: the %v byte memo encrypts to %v bytes and makes the transaction %v bytes, at most %v are allowed",
+			ErrMemoTooLarge, memoLen, EncryptedMemoSize(memoLen), len(data), MaxTransactionSize)
+	}
+	return nil
+}
+
+// x25519PublicKey converts an ed25519 wallet address to its Montgomery form.
+func x25519PublicKey(pubkey common.PublicKey) (*[32]byte, error) {
+	point, err := new(edwards25519.Point).SetBytes(pubkey.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid ed25519 public key %v: %w", pubkey.ToBase58(), err)
+	}
+	var key [32]byte
+	copy(key[:], point.BytesMontgomery())
+	return &key, nil
+}
+
+// x25519PrivateKey derives the x25519 scalar matching x25519PublicKey from an
+// ed25519 keypair, as libsodium's crypto_sign_ed25519_sk_to_curve25519 does.
+func x25519PrivateKey(account types.Account) *[32]byte {
+	h := sha512.Sum512(account.PrivateKey.Seed())
+	var key [32]byte
+	copy(key[:], h[:32])
+	key[0] &= 248
+	key[31] &= 127
+	key[31] |= 64
+	return &key
+}
@@ -0,0 +1,98 @@
+package nft
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/program/memo"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+func TestEncryptMemo(t *testing.T) {
+	receiver := types.NewAccount()
+	for _, plaintext := range [][]byte{
+		[]byte("gm"),
+		[]byte("héllo 👋"),
+		bytes.Repeat([]byte{'x'}, 500),
+	} {
+		encrypted, err := EncryptMemo(receiver.PublicKey, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(encrypted, encryptedMemoPrefix) {
+			t.Errorf("memo %q lacks the %q prefix", encrypted, encryptedMemoPrefix)
+		}
+		if len(encrypted) != EncryptedMemoSize(len(plaintext)) {
+			t.Errorf("memo is %v bytes, EncryptedMemoSize says %v", len(encrypted), EncryptedMemoSize(len(plaintext)))
+		}
+		got, err := DecryptMemo(receiver, encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("decrypted %q, want %q", got, plaintext)
+		}
+	}
+
+	encrypted, err := EncryptMemo(receiver.PublicKey, []byte("gm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := EncryptMemo(receiver.PublicKey, []byte("gm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted == again {
+		t.Error("the same memo encrypted twice alike")
+	}
+	if _, err := DecryptMemo(types.NewAccount(), encrypted); err == nil {
+		t.Error("decrypted with another keypair")
+	}
+	tampered := []byte(encrypted)
+	tampered[len(tampered)-3] ^= 1
+	if _, err := DecryptMemo(receiver, string(tampered)); err == nil {
+		t.Error("decrypted a tampered memo")
+	}
+	for _, memo := range []string{"gm", "", "nacl"} {
+		if _, err := DecryptMemo(receiver, memo); !errors.Is(err, ErrNotEncryptedMemo) {
+			t.Errorf("DecryptMemo(%q) error = %v, want ErrNotEncryptedMemo", memo, err)
+		}
+	}
+	for _, memo := range []string{"nacl:!!!", "nacl:AAAA"} {
+		if _, err := DecryptMemo(receiver, memo); err == nil || errors.Is(err, ErrNotEncryptedMemo) {
+			t.Errorf("DecryptMemo(%q) error = %v, want a decoding error", memo, err)
+		}
+	}
+}
+
+func TestCheckTransactionSize(t *testing.T) {
+	feePayer := types.NewAccount()
+	receiver := types.NewAccount()
+	newTx := func(n int) types.Transaction {
+		encrypted, err := EncryptMemo(receiver.PublicKey, bytes.Repeat([]byte{'x'}, n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx, err := types.NewTransaction(types.NewTransactionParam{
+			Message: types.NewMessage(types.NewMessageParam{
+				FeePayer:        feePayer.PublicKey,
+				RecentBlockhash: testMint.ToBase58(),
+				Instructions:    []types.Instruction{memo.BuildMemo(memo.BuildMemoParam{Memo: []byte(encrypted)})},
+			}),
+			Signers: []types.Account{feePayer},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	if err := checkTransactionSize(newTx(100), 100); err != nil {
+		t.Errorf("a 100 byte memo: %v", err)
+	}
+	if err := checkTransactionSize(newTx(900), 900); !errors.Is(err, ErrMemoTooLarge) {
+		t.Errorf("a 900 byte memo: error = %v, want ErrMemoTooLarge", err)
+	}
+}
@@ -0,0 +1,353 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/pkg/pointer"
+	"github.com/blocto/solana-go-sdk/program/associated_token_account"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// MaxCreators is the most creators a Metaplex metadata account can list.
+const MaxCreators = 5
+
+type MintRequest struct {
+	Receiver common.PublicKey
+	Name     string
+	Symbol   string
+	// URI may be a template: {mint} is replaced with the new mint's address,
+	// for metadata served by an endpoint keyed by mint.
+	URI string
+	// LongNames is how a Name or Symbol over the on-chain limits is handled;
+	// Token-2022 metadata has no such limits.
+	LongNames  LengthPolicy
+	Collection common.PublicKey
+	// Creators share the royalties; their shares must add up to 100. Only
+	// the fee payer, as update authority, may be marked Verified at mint.
+	Creators []token_metadata.Creator
+	// SellerFeeBasisPoints is the secondary sale royalty, 500 being 5%.
+	SellerFeeBasisPoints uint16
+	// Mutable lets the update authority change the metadata later with
+	// UpdateNFT. NFTs are immutable by default.
+	Mutable bool
+	// MaxEditions is how many numbered editions PrintEdition may print; zero
+	// makes a one of one. UnlimitedEditions lifts the cap.
+	MaxEditions       uint64
+	UnlimitedEditions bool
+	// VerifyCollection verifies the item as a member of Collection in the
+	// mint transaction. The fee payer must be the collection's update
+	// authority; otherwise use VerifyCollectionItem signed by the authority.
+	VerifyCollection bool
+	// Programmable mints a programmable NFT (pNFT) whose transfers go
+	// through the token metadata program and its optional RuleSet.
+	Programmable bool
+	RuleSet      common.PublicKey
+	// Token2022 creates the mint under Token-2022, holding its metadata in
+	// the mint through the MetadataPointer and TokenMetadata extensions
+	// instead of a Metaplex metadata account. Such NFTs have no collection,
+	// creators, royalties or editions.
+	Token2022 bool
+	// TransferHook, with Token2022, is a program Token-2022 invokes on every
+	// transfer, e.g. to enforce royalties. TransferHookAccounts are the extra
+	// accounts it needs, registered with the hook program at mint.
+	TransferHook         common.PublicKey
+	TransferHookAccounts []types.AccountMeta
+	// Soulbound mints a Token-2022 NFT with the NonTransferable extension,
+	// so it never leaves the receiver's wallet. It implies Token2022.
+	Soulbound bool
+	// AllowOwnerOffCurve permits a receiver that is not on the ed25519 curve,
+	// e.g. a PDA escrowing the NFT for a program.
+	AllowOwnerOffCurve bool
+}
+
+type MintResult struct {
+	Signature    string
+	Mint         common.PublicKey
+	TokenAccount common.PublicKey
+}
+
+// Mint creates a new NFT (mint, metadata and master edition) held by the
+// receiver's associated token account.
+func (m *Minter) Mint(ctx context.Context, req MintRequest) (*MintResult, error) {
+
+	if !req.AllowOwnerOffCurve && !common.IsOnCurve(req.Receiver) {
+		return nil, fmt.Errorf("receiver %v is off curve, set AllowOwnerOffCurve to mint to a PDA", req.Receiver.ToBase58())
+	}
+
+	if req.RuleSet != (common.PublicKey{}) && !req.Programmable {
+		return nil, fmt.Errorf("a rule set only applies to programmable NFTs")
+	}
+
+	if req.Soulbound {
+		req.Token2022 = true
+	}
+
+	if req.Token2022 && (req.Programmable || req.Collection != (common.PublicKey{}) || len(req.Creators) > 0 ||
+		req.SellerFeeBasisPoints > 0 || req.MaxEditions > 0 || req.UnlimitedEditions) {
+		return nil, fmt.Errorf("Token-2022 NFTs have no collection, creators, royalties, editions or rule set")
+	}
+
+	if req.TransferHook == (common.PublicKey{}) && len(req.TransferHookAccounts) > 0 {
+		return nil, fmt.Errorf("transfer hook accounts need a transfer hook")
+	}
+	if req.TransferHook != (common.PublicKey{}) && !req.Token2022 {
+		return nil, fmt.Errorf("a transfer hook only applies to Token-2022 NFTs")
+	}
+
+	mint := types.NewAccount()
+	uri := ExpandURITemplate(req.URI, mint.PublicKey)
+
+	name, symbol := NormalizeName(req.Name), NormalizeName(req.Symbol)
+	if !req.Token2022 {
+		var err error
+		if name, symbol, err = fitMetadataStrings(req.Name, req.Symbol, uri, req.LongNames); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkCreators(req.Creators, req.SellerFeeBasisPoints, m.feePayer.PublicKey); err != nil {
+		return nil, err
+	}
+
+	var creators *[]token_metadata.Creator
+	if len(req.Creators) > 0 {
+		creators = &req.Creators
+	}
+
+	var collection *token_metadata.Collection
+	var verify []types.Instruction
+	if req.Collection != (common.PublicKey{}) {
+		collection = &token_metadata.Collection{
+			Verified: false,
+			Key:      req.Collection,
+		}
+
+		if req.VerifyCollection {
+			verifyInstruction := m.verifyCollectionInstruction
+			if req.Programmable {
+				verifyInstruction = m.verifyCollectionV1Instruction
+			}
+			instruction, err := verifyInstruction(ctx, mint.PublicKey, req.Collection, m.feePayer.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			verify = append(verify, instruction)
+		}
+	}
+
+	// zero prints unless editions are asked for
+	maxSupply := pointer.Get(req.MaxEditions)
+	if req.UnlimitedEditions {
+		maxSupply = nil
+	}
+
+	return m.mintNFT(ctx, nftParams{
+		mint:     mint,
+		receiver: req.Receiver,
+		data: token_metadata.DataV2{
+			Name:                 name,
+			Symbol:               symbol,
+			Uri:                  uri,
+			SellerFeeBasisPoints: req.SellerFeeBasisPoints,
+			Creators:             creators,
+			Collection:           collection,
+			Uses:                 nil,
+		},
+		mutable:      req.Mutable,
+		maxSupply:    maxSupply,
+		programmable: req.Programmable,
+		ruleSet:      req.RuleSet,
+		token2022:    req.Token2022,
+		transferHook: req.TransferHook,
+		hookAccounts: req.TransferHookAccounts,
+		soulbound:    req.Soulbound,
+		extra:        verify,
+	})
+}
+
+// CheckMintRequest runs the checks of Mint that need no RPC call: the name,
+// symbol and URI limits under req.LongNames, the creator rules and that only
+// feePayer is marked a verified creator. Batches use it to refuse a bad
+// request before minting any. A URI template is checked with the shortest
+// mint address it can expand to.
+func CheckMintRequest(req MintRequest, feePayer common.PublicKey) error {
+	if !req.Token2022 {
+		uri := ExpandURITemplate(req.URI, common.PublicKey{})
+		if _, _, err := fitMetadataStrings(req.Name, req.Symbol, uri, req.LongNames); err != nil {
+			return err
+		}
+	}
+	return checkCreators(req.Creators, req.SellerFeeBasisPoints, feePayer)
+}
+
+// checkCreators applies validateRoyalties and refuses a verified creator
+// other than feePayer, the only one signing the mint.
+func checkCreators(creators []token_metadata.Creator, sellerFeeBasisPoints uint16, feePayer common.PublicKey) error {
+	if err := validateRoyalties(creators, sellerFeeBasisPoints); err != nil {
+		return err
+	}
+	for _, creator := range creators {
+		if creator.Verified && creator.Address != feePayer {
+			return fmt.Errorf("creator %v cannot be verified at mint, only the fee payer signs it", creator.Address.ToBase58())
+		}
+	}
+	return nil
+}
+
+// validateRoyalties applies the metadata program's creator rules up front,
+// so a bad list fails before any rent is spent.
+func validateRoyalties(creators []token_metadata.Creator, sellerFeeBasisPoints uint16) error {
+	if sellerFeeBasisPoints > 10000 {
+		return fmt.Errorf("seller fee of %v basis points is over 10000", sellerFeeBasisPoints)
+	}
+	if len(creators) == 0 {
+		return nil
+	}
+	if len(creators) > MaxCreators {
+		return fmt.Errorf("%v creators given, at most %v are allowed", len(creators), MaxCreators)
+	}
+
+	seen := map[common.PublicKey]bool{}
+	total := 0
+	for _, creator := range creators {
+		if seen[creator.Address] {
+			return fmt.Errorf("creator %v is listed twice", creator.Address.ToBase58())
+		}
+		seen[creator.Address] = true
+		total += int(creator.Share)
+	}
+	if total != 100 {
+		return fmt.Errorf("creator shares add up to %v, want 100", total)
+	}
+	return nil
+}
+
+type nftParams struct {
+	mint     types.Account
+	receiver common.PublicKey
+	data     token_metadata.DataV2
+	mutable  bool
+	// maxSupply is the number of printable editions, nil for unlimited.
+	maxSupply         *uint64
+	collectionDetails *token_metadata.CollectionDetails
+	programmable      bool
+	ruleSet           common.PublicKey
+	token2022         bool
+	transferHook      common.PublicKey
+	hookAccounts      []types.AccountMeta
+	soulbound         bool
+	// extra instructions run last, once the NFT exists.
+	extra []types.Instruction
+}
+
+// mintNFT sends a single transaction creating the mint, its metadata and
+// master edition, and mints the one token to the receiver's ATA.
+func (m *Minter) mintNFT(ctx context.Context, params nftParams) (*MintResult, error) {
+
+	if params.programmable {
+		return m.mintProgrammable(ctx, params)
+	}
+	if params.token2022 {
+		return m.mintToken2022(ctx, params)
+	}
+
+	feePayer := m.feePayer
+	mint, receiver := params.mint, params.receiver
+
+	ata, _, err := common.FindAssociatedTokenAddress(receiver, mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid ata: %w", err)
+	}
+
+	tokenMetadataPubkey, err := token_metadata.GetTokenMetaPubkey(mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	tokenMasterEditionPubkey, err := token_metadata.GetMasterEdition(mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid master edition: %w", err)
+	}
+
+	mintAccountRent, err := m.client.GetMinimumBalanceForRentExemption(ctx, token.MintAccountSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint account rent: %w", err)
+	}
+
+	recentBlockhashResponse, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	instructions := []types.Instruction{
+		system.CreateAccount(system.CreateAccountParam{
+			From:     feePayer.PublicKey,
+			New:      mint.PublicKey,
+			Owner:    common.TokenProgramID,
+			Lamports: mintAccountRent,
+			Space:    token.MintAccountSize,
+		}),
+		token.InitializeMint(token.InitializeMintParam{
+			Decimals:   0,
+			Mint:       mint.PublicKey,
+			MintAuth:   feePayer.PublicKey,
+			FreezeAuth: &feePayer.PublicKey,
+		}),
+		token_metadata.CreateMetadataAccountV3(token_metadata.CreateMetadataAccountV3Param{
+			Metadata:                tokenMetadataPubkey,
+			Mint:                    mint.PublicKey,
+			MintAuthority:           feePayer.PublicKey,
+			Payer:                   feePayer.PublicKey,
+			UpdateAuthority:         feePayer.PublicKey,
+			UpdateAuthorityIsSigner: true,
+			IsMutable:               params.mutable,
+			Data:                    params.data,
+			CollectionDetails:       params.collectionDetails,
+		}),
+		associated_token_account.CreateIdempotent(associated_token_account.CreateIdempotentParam{
+			Funder:                 feePayer.PublicKey,
+			Owner:                  receiver,
+			Mint:                   mint.PublicKey,
+			AssociatedTokenAccount: ata,
+		}),
+		token.MintTo(token.MintToParam{
+			Mint:   mint.PublicKey,
+			To:     ata,
+			Auth:   feePayer.PublicKey,
+			Amount: 1,
+		}),
+		token_metadata.CreateMasterEditionV3(token_metadata.CreateMasterEditionParam{
+			Edition:         tokenMasterEditionPubkey,
+			Mint:            mint.PublicKey,
+			UpdateAuthority: feePayer.PublicKey,
+			MintAuthority:   feePayer.PublicKey,
+			Metadata:        tokenMetadataPubkey,
+			Payer:           feePayer.PublicKey,
+			MaxSupply:       params.maxSupply,
+		}),
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: []types.Account{mint, feePayer},
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: recentBlockhashResponse.Blockhash,
+			Instructions:    append(instructions, params.extra...),
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new a tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &MintResult{Signature: txSig, Mint: mint.PublicKey, TokenAccount: ata}, nil
+}
@@ -0,0 +1,71 @@
+// Package nft mints, transfers and inspects Metaplex NFTs on Solana.
+package nft
+
+import (
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// Minter sends NFT transactions through an RPC client, paying fees and rent
+// from a single fee payer. Read-only callers may pass a zero fee payer.
+type Minter struct {
+	client   *client.Client
+	feePayer types.Account
+
+	// Commitment is used for account reads, blockhashes, preflight checks
+	// and confirmation.
+	Commitment rpc.Commitment
+
+	// TxVersion selects the message format of built transactions. Messages
+	// that reference address lookup tables are always built as v0.
+	TxVersion types.MessageVersion
+
+	// Screening, when set, is consulted before every transfer.
+	Screening *ScreeningHook
+
+	// WSEndpoint serves signatureSubscribe for WaitForConfirmation; when
+	// empty, confirmations are polled.
+	WSEndpoint string
+
+	// DAS serves compressed NFT assets and proofs; calls that need it fail
+	// with ErrNoDAS when it is nil.
+	DAS *DASClient
+
+	// History, when set, records every metadata update for Rollback.
+	History *MetadataHistory
+}
+
+// NewMinter returns a Minter sending through c and paying from feePayer.
+func NewMinter(c *client.Client, feePayer types.Account) *Minter {
+	return &Minter{
+		client:     c,
+		feePayer:   feePayer,
+		Commitment: rpc.CommitmentConfirmed,
+		TxVersion:  types.MessageVersionLegacy,
+	}
+}
+
+// Client returns the RPC client the Minter sends through.
+func (m *Minter) Client() *client.Client {
+	return m.client
+}
+
+// FeePayer returns the account paying fees and rent.
+func (m *Minter) FeePayer() types.Account {
+	return m.feePayer
+}
+
+func (m *Minter) newMessage(param types.NewMessageParam) types.Message {
+	return NewMessage(m.TxVersion, param)
+}
+
+// NewMessage builds a message in the given format, legacy unless version is
+// v0.
+func NewMessage(version types.MessageVersion, param types.NewMessageParam) types.Message {
+	msg := types.NewMessage(param)
+	if version == types.MessageVersionV0 {
+		msg.Version = types.MessageVersionV0
+	}
+	return msg
+}
@@ -0,0 +1,84 @@
+package nft
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// On-chain limits of the token metadata program, in UTF-8 bytes.
+const (
+	MaxNameLength   = 32
+	MaxSymbolLength = 10
+	MaxURILength    = 200
+)
+
+// LengthPolicy is how a name or symbol over its on-chain limit is handled.
+type LengthPolicy int
+
+const (
+	// RejectLongNames fails the mint before anything is sent.
+	RejectLongNames LengthPolicy = iota
+	// TruncateLongNames cuts the string after the last whole grapheme
+	// cluster that fits, so an emoji is never split. The full name stays in
+	// the off-chain JSON at the URI.
+	TruncateLongNames
+)
+
+// NormalizeName puts a name or symbol in NFC form and strips control
+// characters, which include the zero bytes padding on-chain strings. The
+// zero-width joiners of emoji sequences are kept.
+func NormalizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, norm.NFC.String(s))
+}
+
+// ExpandURITemplate replaces {mint} in uri with the mint address.
+func ExpandURITemplate(uri string, mint common.PublicKey) string {
+	return strings.ReplaceAll(uri, "{mint}", mint.ToBase58())
+}
+
+// fitMetadataStrings normalizes name and symbol and applies policy to them.
+// A URI over its limit is always an error, as a truncated one would point
+// elsewhere.
+func fitMetadataStrings(name, symbol, uri string, policy LengthPolicy) (string, string, error) {
+	if len(uri) > MaxURILength {
+		return "", "", fmt.Errorf("uri is %v bytes, at most %v are allowed", len(uri), MaxURILength)
+	}
+	name, err := fitString("name", NormalizeName(name), MaxNameLength, policy)
+	if err != nil {
+		return "", "", err
+	}
+	symbol, err = fitString("symbol", NormalizeName(symbol), MaxSymbolLength, policy)
+	if err != nil {
+		return "", "", err
+	}
+	return name, symbol, nil
+}
+
+func fitString(field, s string, max int, policy LengthPolicy) (string, error) {
+	if len(s) <= max {
+		return s, nil
+	}
+	if policy != TruncateLongNames {
+		return "", fmt.Errorf("%v %q is %v bytes, at most %v are allowed", field, s, len(s), max)
+	}
+	end := 0
+	graphemes := uniseg.NewGraphemes(s)
+	for graphemes.Next() {
+		_, to := graphemes.Positions()
+		if to > max {
+			break
+		}
+		end = to
+	}
+	return s[:end], nil
+}
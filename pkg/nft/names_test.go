@@ -0,0 +1,87 @@
+package nft
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFitMetadataStrings(t *testing.T) {
+	thirty := strings.Repeat("a", 30)
+	tests := []struct {
+		name, symbol, uri string
+		policy            LengthPolicy
+		wantName          string
+		wantSymbol        string
+		wantErr           string
+	}{
+		{
+			name:       "été",
+			symbol:     "SYM",
+			wantName:   "été",
+			wantSymbol: "SYM",
+		},
+		{
+			name:       "Ape\x00\x00\x00",
+			symbol:     "A\nPE",
+			wantName:   "Ape",
+			wantSymbol: "APE",
+		},
+		{
+			// the zero-width joiners of a family emoji are kept
+			name:       "\U0001F468‍\U0001F469‍\U0001F467",
+			wantName:   "\U0001F468‍\U0001F469‍\U0001F467",
+			wantSymbol: "",
+		},
+		{
+			name:       strings.Repeat("b", 32),
+			symbol:     strings.Repeat("S", 10),
+			wantName:   strings.Repeat("b", 32),
+			wantSymbol: strings.Repeat("S", 10),
+		},
+		{
+			name:    thirty + "\U0001F44D\U0001F3FD",
+			wantErr: "name",
+		},
+		{
+			name:    "Ape",
+			symbol:  strings.Repeat("S", 11),
+			wantErr: "symbol",
+		},
+		{
+			// the thumbs up and its skin tone go together
+			name:       thirty + "\U0001F44D\U0001F3FD",
+			policy:     TruncateLongNames,
+			wantName:   thirty,
+			wantSymbol: "",
+		},
+		{
+			name:       strings.Repeat("é", 17),
+			symbol:     "éééééé",
+			policy:     TruncateLongNames,
+			wantName:   strings.Repeat("é", 16),
+			wantSymbol: "ééééé",
+		},
+		{
+			name:    "Ape",
+			uri:     "https://example.com/" + strings.Repeat("x", MaxURILength),
+			policy:  TruncateLongNames,
+			wantErr: "uri",
+		},
+	}
+	for _, tt := range tests {
+		name, symbol, err := fitMetadataStrings(tt.name, tt.symbol, tt.uri, tt.policy)
+		if tt.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("fitMetadataStrings(%q, %q) error = %v, want a %v error", tt.name, tt.symbol, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("fitMetadataStrings(%q, %q): %v", tt.name, tt.symbol, err)
+			continue
+		}
+		if name != tt.wantName || symbol != tt.wantSymbol {
+			t.Errorf("fitMetadataStrings(%q, %q) = %q, %q, want %q, %q", tt.name, tt.symbol, name, symbol, tt.wantName, tt.wantSymbol)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package nft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// PlanVersion is the layout version written into every Plan.
+const PlanVersion = 1
+
+var ErrPlanMemo = errors.New("memos are encrypted with a random key and cannot be planned")
+
+// Plan is an unsigned transaction laid out for review. Building the same
+// request against the same blockhash and chain state yields an identical
+// Plan, so reviewers can regenerate it and diff before anyone signs.
+type Plan struct {
+	Version         int               `json:"version"`
+	FeePayer        string            `json:"feePayer"`
+	RecentBlockhash string            `json:"recentBlockhash"`
+	Signers         []string          `json:"signers"`
+	Instructions    []PlanInstruction `json:"instructions"`
+	// MessageSHA256 is the digest of the message every signer signs.
+	MessageSHA256 string `json:"messageSha256"`
+	// Transaction is the base64 serialized transaction with empty
+	// signatures.
+	Transaction string `json:"transaction"`
+}
+
+type PlanInstruction struct {
+	ProgramID string        `json:"programId"`
+	Accounts  []PlanAccount `json:"accounts"`
+	Data      string        `json:"data"`
+}
+
+type PlanAccount struct {
+	Address  string `json:"address"`
+	Signer   bool   `json:"signer"`
+	Writable bool   `json:"writable"`
+}
+
+// PlanTransfer builds the transfer Transfer would send, without signing it.
+// Only the sender's public key is used and req.Memo must be empty.
+func (m *Minter) PlanTransfer(ctx context.Context, req TransferRequest, recentBlockhash string) (*Plan, error) {
+	if len(req.Memo) > 0 {
+		return nil, ErrPlanMemo
+	}
+	instructions, _, err := m.transferInstructions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return m.newPlan(instructions, recentBlockhash)
+}
+
+// PlanUpdate builds the update UpdateNFT would send, without signing it.
+// authority is the update authority that will sign.
+func (m *Minter) PlanUpdate(ctx context.Context, req UpdateRequest, authority common.PublicKey, recentBlockhash string) (*Plan, error) {
+	instruction, _, _, err := m.updateInstruction(ctx, req, authority)
+	if err != nil {
+		return nil, err
+	}
+	return m.newPlan([]types.Instruction{instruction}, recentBlockhash)
+}
+
+func (m *Minter) newPlan(instructions []types.Instruction, recentBlockhash string) (*Plan, error) {
+
+	msg := m.newMessage(types.NewMessageParam{
+		FeePayer:        m.feePayer.PublicKey,
+		RecentBlockhash: recentBlockhash,
+		Instructions:    instructions,
+	})
+	tx, err := types.NewTransaction(types.NewTransactionParam{Message: msg})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	msgData, err := msg.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize message: %w", err)
+	}
+	txData, err := tx.Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize tx: %w", err)
+	}
+	digest := sha256.Sum256(msgData)
+
+	plan := &Plan{
+		Version:         PlanVersion,
+		FeePayer:        m.feePayer.PublicKey.ToBase58(),
+		RecentBlockhash: recentBlockhash,
+		MessageSHA256:   hex.EncodeToString(digest[:]),
+		Transaction:     base64.StdEncoding.EncodeToString(txData),
+	}
+	for _, signer := range msg.Accounts[:msg.Header.NumRequireSignatures] {
+		plan.Signers = append(plan.Signers, signer.ToBase58())
+	}
+	for _, instruction := range instructions {
+		planned := PlanInstruction{
+			ProgramID: instruction.ProgramID.ToBase58(),
+			Data:      base64.StdEncoding.EncodeToString(instruction.Data),
+		}
+		for _, account := range instruction.Accounts {
+			planned.Accounts = append(planned.Accounts, PlanAccount{
+				Address:  account.PubKey.ToBase58(),
+				Signer:   account.IsSigner,
+				Writable: account.IsWritable,
+			})
+		}
+		plan.Instructions = append(plan.Instructions, planned)
+	}
+	return plan, nil
+}
@@ -0,0 +1,301 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/compute_budget"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/near/borsh-go"
+)
+
+// AuthorizationRulesProgramID evaluates the rule sets of programmable NFTs.
+var AuthorizationRulesProgramID = common.PublicKeyFromString("auth9SigNpDKz4sJJ1DfCTuZrZNSAgh9sFD3rboVmgg")
+
+// omittedAccount fills the slot of an optional account the token metadata
+// program should treat as absent.
+var omittedAccount = common.MetaplexTokenMetaProgramID
+
+// programmableComputeUnits covers Create plus Mint, or Transfer, of a pNFT,
+// which exceed the default limit of an instruction.
+const programmableComputeUnits = 400_000
+
+// assetData is the token metadata AssetData of the Create instruction.
+type assetData struct {
+	Name                 string
+	Symbol               string
+	Uri                  string
+	SellerFeeBasisPoints uint16
+	Creators             *[]token_metadata.Creator
+	PrimarySaleHappened  bool
+	IsMutable            bool
+	TokenStandard        token_metadata.TokenStandard
+	Collection           *token_metadata.Collection
+	Uses                 *token_metadata.Uses
+	CollectionDetails    *token_metadata.CollectionDetails
+	RuleSet              *common.PublicKey
+}
+
+// printSupply variants must be structs for borsh to encode their payload.
+type printSupply struct {
+	Enum      borsh.Enum `borsh_enum:"true"`
+	Zero      struct{}
+	Limited   struct{ Supply uint64 }
+	Unlimited struct{}
+}
+
+// tokenRecordAddress derives the token record PDA that tracks the state and
+// delegate of a pNFT token account.
+func tokenRecordAddress(mint, tokenAccount common.PublicKey) (common.PublicKey, error) {
+	tokenRecord, _, err := common.FindProgramAddress(
+		[][]byte{
+			[]byte("metadata"),
+			common.MetaplexTokenMetaProgramID.Bytes(),
+			mint.Bytes(),
+			[]byte("token_record"),
+			tokenAccount.Bytes(),
+		},
+		common.MetaplexTokenMetaProgramID,
+	)
+	return tokenRecord, err
+}
+
+// mintProgrammable creates a pNFT with the token metadata Create and Mint
+// instructions, which also create the mint, the receiver's ATA and its token
+// record.
+func (m *Minter) mintProgrammable(ctx context.Context, params nftParams) (*MintResult, error) {
+
+	feePayer := m.feePayer
+	mint, receiver := params.mint, params.receiver
+
+	ata, _, err := common.FindAssociatedTokenAddress(receiver, mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid ata: %w", err)
+	}
+	tokenMetadataPubkey, err := token_metadata.GetTokenMetaPubkey(mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	tokenMasterEditionPubkey, err := token_metadata.GetMasterEdition(mint.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid master edition: %w", err)
+	}
+	tokenRecord, err := tokenRecordAddress(mint.PublicKey, ata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid token record: %w", err)
+	}
+
+	supply := &printSupply{Enum: 2} // Unlimited
+	if params.maxSupply != nil {
+		supply = &printSupply{Enum: 0} // Zero
+		if *params.maxSupply > 0 {
+			supply = &printSupply{Enum: 1}
+			supply.Limited.Supply = *params.maxSupply
+		}
+	}
+
+	var ruleSet *common.PublicKey
+	authorizationRulesProgram, authorizationRules := omittedAccount, omittedAccount
+	if params.ruleSet != (common.PublicKey{}) {
+		ruleSet = &params.ruleSet
+		authorizationRulesProgram, authorizationRules = AuthorizationRulesProgramID, params.ruleSet
+	}
+
+	createData, err := borsh.Serialize(struct {
+		Instruction token_metadata.Instruction
+		Version     uint8
+		AssetData   assetData
+		Decimals    *uint8
+		PrintSupply *printSupply
+	}{
+		Instruction: token_metadata.InstructionCreate,
+		Version:     0, // V1
+		AssetData: assetData{
+			Name:                 params.data.Name,
+			Symbol:               params.data.Symbol,
+			Uri:                  params.data.Uri,
+			SellerFeeBasisPoints: params.data.SellerFeeBasisPoints,
+			Creators:             params.data.Creators,
+			IsMutable:            params.mutable,
+			TokenStandard:        token_metadata.ProgrammableNonFungible,
+			Collection:           params.data.Collection,
+			Uses:                 params.data.Uses,
+			CollectionDetails:    params.collectionDetails,
+			RuleSet:              ruleSet,
+		},
+		Decimals:    new(uint8),
+		PrintSupply: supply,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize create instruction: %w", err)
+	}
+
+	mintData, err := borsh.Serialize(struct {
+		Instruction       token_metadata.Instruction
+		Version           uint8
+		Amount            uint64
+		AuthorizationData *struct{}
+	}{
+		Instruction: token_metadata.InstructionMint,
+		Version:     0, // V1
+		Amount:      1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize mint instruction: %w", err)
+	}
+
+	recentBlockhashResponse, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	instructions := []types.Instruction{
+		compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+			Units: programmableComputeUnits,
+		}),
+		{
+			ProgramID: common.MetaplexTokenMetaProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: tokenMetadataPubkey, IsSigner: false, IsWritable: true},
+				{PubKey: tokenMasterEditionPubkey, IsSigner: false, IsWritable: true},
+				{PubKey: mint.PublicKey, IsSigner: true, IsWritable: true},
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // mint authority
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: true},  // payer
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // update authority
+				{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+				{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+				{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+			},
+			Data: createData,
+		},
+		{
+			ProgramID: common.MetaplexTokenMetaProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: ata, IsSigner: false, IsWritable: true},
+				{PubKey: receiver, IsSigner: false, IsWritable: false},
+				{PubKey: tokenMetadataPubkey, IsSigner: false, IsWritable: false},
+				{PubKey: tokenMasterEditionPubkey, IsSigner: false, IsWritable: false},
+				{PubKey: tokenRecord, IsSigner: false, IsWritable: true},
+				{PubKey: mint.PublicKey, IsSigner: false, IsWritable: true},
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // authority
+				{PubKey: omittedAccount, IsSigner: false, IsWritable: false},    // delegate record
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: true},  // payer
+				{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+				{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+				{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+				{PubKey: common.SPLAssociatedTokenAccountProgramID, IsSigner: false, IsWritable: false},
+				{PubKey: authorizationRulesProgram, IsSigner: false, IsWritable: false},
+				{PubKey: authorizationRules, IsSigner: false, IsWritable: false},
+			},
+			Data: mintData,
+		},
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: []types.Account{mint, feePayer},
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: recentBlockhashResponse.Blockhash,
+			Instructions:    append(instructions, params.extra...),
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new a tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &MintResult{Signature: txSig, Mint: mint.PublicKey, TokenAccount: ata}, nil
+}
+
+// programmableTransferInstruction builds the token metadata Transfer
+// instruction, which thaws the pNFT, moves it, freezes it again and creates
+// the receiver's ATA and token record. ruleSet is nil when the pNFT has none.
+func programmableTransferInstruction(mint, owner, receiver, payer common.PublicKey, ruleSet *common.PublicKey) (types.Instruction, error) {
+
+	ownerAta, _, err := common.FindAssociatedTokenAddress(owner, mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find sender's ATA: %w", err)
+	}
+	receiverAta, _, err := common.FindAssociatedTokenAddress(receiver, mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find recipient's ATA: %w", err)
+	}
+	metadata, err := token_metadata.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	masterEdition, err := token_metadata.GetMasterEdition(mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid master edition: %w", err)
+	}
+	ownerTokenRecord, err := tokenRecordAddress(mint, ownerAta)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid token record: %w", err)
+	}
+	receiverTokenRecord, err := tokenRecordAddress(mint, receiverAta)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid token record: %w", err)
+	}
+
+	authorizationRulesProgram, authorizationRules := omittedAccount, omittedAccount
+	if ruleSet != nil {
+		authorizationRulesProgram, authorizationRules = AuthorizationRulesProgramID, *ruleSet
+	}
+
+	data, err := borsh.Serialize(struct {
+		Instruction       token_metadata.Instruction
+		Version           uint8
+		Amount            uint64
+		AuthorizationData *struct{}
+	}{
+		Instruction: token_metadata.InstructionTransfer,
+		Version:     0, // V1
+		Amount:      1,
+	})
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to serialize transfer instruction: %w", err)
+	}
+
+	return types.Instruction{
+		ProgramID: common.MetaplexTokenMetaProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: ownerAta, IsSigner: false, IsWritable: true},
+			{PubKey: owner, IsSigner: false, IsWritable: false},
+			{PubKey: receiverAta, IsSigner: false, IsWritable: true},
+			{PubKey: receiver, IsSigner: false, IsWritable: false},
+			{PubKey: mint, IsSigner: false, IsWritable: false},
+			{PubKey: metadata, IsSigner: false, IsWritable: true},
+			{PubKey: masterEdition, IsSigner: false, IsWritable: false},
+			{PubKey: ownerTokenRecord, IsSigner: false, IsWritable: true},
+			{PubKey: receiverTokenRecord, IsSigner: false, IsWritable: true},
+			{PubKey: owner, IsSigner: true, IsWritable: false}, // authority
+			{PubKey: payer, IsSigner: true, IsWritable: true},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+			{PubKey: common.TokenProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: common.SPLAssociatedTokenAccountProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: authorizationRulesProgram, IsSigner: false, IsWritable: false},
+			{PubKey: authorizationRules, IsSigner: false, IsWritable: false},
+		},
+		Data: data,
+	}, nil
+}
+
+// isProgrammable reports whether metadata belongs to a pNFT and returns its
+// rule set, if any.
+func isProgrammable(metadata token_metadata.Metadata) (bool, *common.PublicKey) {
+	if metadata.TokenStandard == nil || *metadata.TokenStandard != token_metadata.ProgrammableNonFungible {
+		return false, nil
+	}
+	if metadata.ProgrammableConfig == nil {
+		return true, nil
+	}
+	return true, metadata.ProgrammableConfig.V1.RuleSet
+}
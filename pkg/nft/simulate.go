@@ -0,0 +1,186 @@
+package nft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// SimulationReport is a simulateTransaction result broken down per
+// top-level instruction.
+type SimulationReport struct {
+	// Err is the transaction error as JSON, empty when it would succeed.
+	Err           string              `json:"err,omitempty"`
+	UnitsConsumed uint64              `json:"unitsConsumed"`
+	Instructions  []InstructionReport `json:"instructions"`
+	AccountWrites []AccountWrite      `json:"accountWrites"`
+	ReturnData    *ReturnData         `json:"returnData,omitempty"`
+	Logs          []string            `json:"logs"`
+}
+
+type InstructionReport struct {
+	Index     int    `json:"index"`
+	ProgramID string `json:"programId"`
+	// UnitsConsumed includes the units of the instruction's inner calls.
+	UnitsConsumed uint64 `json:"unitsConsumed"`
+	// InnerInvocations counts the cross-program invocations it made.
+	InnerInvocations int      `json:"innerInvocations"`
+	Error            string   `json:"error,omitempty"`
+	Logs             []string `json:"logs"`
+}
+
+// AccountWrite is the simulated state of a writable account next to its
+// current state. Missing accounts have zero lamports and no owner.
+type AccountWrite struct {
+	Address        string `json:"address"`
+	LamportsBefore uint64 `json:"lamportsBefore"`
+	LamportsAfter  uint64 `json:"lamportsAfter"`
+	OwnerBefore    string `json:"ownerBefore,omitempty"`
+	OwnerAfter     string `json:"ownerAfter,omitempty"`
+	DataLenBefore  int    `json:"dataLenBefore"`
+	DataLenAfter   int    `json:"dataLenAfter"`
+	DataChanged    bool   `json:"dataChanged"`
+}
+
+type ReturnData struct {
+	ProgramID string `json:"programId"`
+	Data      []byte `json:"data"`
+}
+
+// Simulate runs tx against the cluster without sending it. Signatures are
+// not checked and the blockhash is replaced, so unsigned or stale
+// transactions can be simulated. Writable accounts loaded through address
+// lookup tables are not included in AccountWrites.
+func (m *Minter) Simulate(ctx context.Context, tx types.Transaction) (*SimulationReport, error) {
+
+	writable := writableAccounts(tx.Message)
+	addresses := make([]string, len(writable))
+	for i, account := range writable {
+		addresses[i] = account.ToBase58()
+	}
+
+	before, err := m.client.GetMultipleAccountsWithConfig(ctx, addresses, client.GetMultipleAccountsConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get writable accounts: %w", err)
+	}
+
+	simulation, err := m.client.SimulateTransactionWithConfig(ctx, tx, client.SimulateTransactionConfig{
+		Commitment:             m.Commitment,
+		ReplaceRecentBlockhash: true,
+		Addresses:              addresses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate tx: %w", err)
+	}
+
+	report := &SimulationReport{
+		Instructions: parseInstructionLogs(simulation.Logs),
+		Logs:         simulation.Logs,
+	}
+	if simulation.Err != nil {
+		errJSON, err := json.Marshal(simulation.Err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode simulation error: %w", err)
+		}
+		report.Err = string(errJSON)
+	}
+	if simulation.UnitConsumed != nil {
+		report.UnitsConsumed = *simulation.UnitConsumed
+	}
+	if simulation.ReturnData != nil {
+		report.ReturnData = &ReturnData{
+			ProgramID: simulation.ReturnData.ProgramId.ToBase58(),
+			Data:      simulation.ReturnData.Data,
+		}
+	}
+
+	// accounts are only returned when the simulation succeeds
+	if len(simulation.Accounts) == len(writable) {
+		for i, account := range writable {
+			write := AccountWrite{Address: account.ToBase58()}
+			if i < len(before) {
+				write.LamportsBefore = before[i].Lamports
+				write.DataLenBefore = len(before[i].Data)
+				if before[i].Owner != (common.PublicKey{}) {
+					write.OwnerBefore = before[i].Owner.ToBase58()
+				}
+			}
+			if after := simulation.Accounts[i]; after != nil {
+				write.LamportsAfter = after.Lamports
+				write.DataLenAfter = len(after.Data)
+				if after.Owner != (common.PublicKey{}) {
+					write.OwnerAfter = after.Owner.ToBase58()
+				}
+				write.DataChanged = i >= len(before) || string(before[i].Data) != string(after.Data)
+			}
+			report.AccountWrites = append(report.AccountWrites, write)
+		}
+	}
+
+	return report, nil
+}
+
+// writableAccounts returns the message's static account keys the
+// transaction may write, following the header's signer/readonly layout.
+func writableAccounts(msg types.Message) []common.PublicKey {
+	signers := int(msg.Header.NumRequireSignatures)
+	writableSigners := signers - int(msg.Header.NumReadonlySignedAccounts)
+	writableUnsigned := len(msg.Accounts) - int(msg.Header.NumReadonlyUnsignedAccounts)
+
+	var writable []common.PublicKey
+	for i, account := range msg.Accounts {
+		if (i < signers && i < writableSigners) || (i >= signers && i < writableUnsigned) {
+			writable = append(writable, account)
+		}
+	}
+	return writable
+}
+
+// parseInstructionLogs splits runtime logs at each top-level "invoke [1]"
+// and reads compute units and failures off the program's closing lines.
+func parseInstructionLogs(logs []string) []InstructionReport {
+	var reports []InstructionReport
+	depth := 0
+
+	for _, line := range logs {
+		fields := strings.Fields(line)
+
+		if len(fields) == 4 && fields[0] == "Program" && fields[2] == "invoke" {
+			depth, _ = strconv.Atoi(strings.Trim(fields[3], "[]"))
+			if depth == 1 {
+				reports = append(reports, InstructionReport{Index: len(reports), ProgramID: fields[1]})
+			} else if len(reports) > 0 {
+				reports[len(reports)-1].InnerInvocations++
+			}
+		}
+		if len(reports) == 0 {
+			continue
+		}
+		current := &reports[len(reports)-1]
+		current.Logs = append(current.Logs, line)
+
+		if len(fields) < 3 || fields[0] != "Program" || fields[1] == "log:" || fields[1] == "data:" || fields[1] == "return:" {
+			continue
+		}
+		switch {
+		case depth == 1 && len(fields) >= 4 && fields[2] == "consumed" && fields[1] == current.ProgramID:
+			if units, err := strconv.ParseUint(fields[3], 10, 64); err == nil {
+				current.UnitsConsumed = units
+			}
+		case fields[2] == "success":
+			depth--
+		case fields[2] == "failed:":
+			if depth == 1 {
+				current.Error = strings.Join(fields[3:], " ")
+			}
+			depth--
+		}
+	}
+	return reports
+}
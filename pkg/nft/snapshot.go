@@ -0,0 +1,211 @@
+package nft
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/rpc"
+)
+
+// SnapshotSource is how the members of a collection are enumerated.
+type SnapshotSource int
+
+const (
+	// SnapshotAuto uses the DAS API when the endpoint serves it and
+	// getProgramAccounts otherwise.
+	SnapshotAuto SnapshotSource = iota
+	// SnapshotDAS pages through getAssetsByGroup, compressed NFTs included.
+	SnapshotDAS
+	// SnapshotProgramAccounts scans the metadata accounts sharing the
+	// collection's update authority and looks up the holder of each member
+	// mint. It only finds uncompressed members of the classic token program.
+	SnapshotProgramAccounts
+)
+
+// CollectionHolder is a wallet holding members of a collection.
+type CollectionHolder struct {
+	Owner common.PublicKey
+	Mints []common.PublicKey
+}
+
+// CollectionMember is a verified member of a collection.
+type CollectionMember struct {
+	// Mint is the asset ID of a compressed member.
+	Mint common.PublicKey
+	URI  string
+	// Owner is only known when the members were read through DAS.
+	Owner common.PublicKey
+}
+
+// CollectionMembers returns the verified members of collection that have not
+// been burned.
+func (m *Minter) CollectionMembers(ctx context.Context, collection common.PublicKey, source SnapshotSource) ([]CollectionMember, error) {
+	switch source {
+	case SnapshotAuto:
+		if m.DAS != nil {
+			members, err := m.collectionMembersDAS(ctx, collection)
+			if !errors.Is(err, ErrDASUnsupported) {
+				return members, err
+			}
+		}
+		return m.collectionMembersProgramAccounts(ctx, collection)
+	case SnapshotDAS:
+		if m.DAS == nil {
+			return nil, ErrNoDAS
+		}
+		return m.collectionMembersDAS(ctx, collection)
+	case SnapshotProgramAccounts:
+		return m.collectionMembersProgramAccounts(ctx, collection)
+	default:
+		return nil, fmt.Errorf("unknown snapshot source %v", source)
+	}
+}
+
+// SnapshotHolders returns every wallet holding a verified member of
+// collection, with the mints it holds, sorted by wallet.
+func (m *Minter) SnapshotHolders(ctx context.Context, collection common.PublicKey, source SnapshotSource) ([]CollectionHolder, error) {
+
+	members, err := m.CollectionMembers(ctx, collection, source)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings := map[common.PublicKey][]common.PublicKey{}
+	for _, member := range members {
+		owner := member.Owner
+		if owner == (common.PublicKey{}) {
+			var held bool
+			if owner, held, err = m.mintHolder(ctx, member.Mint); err != nil {
+				return nil, err
+			}
+			if !held {
+				continue
+			}
+		}
+		holdings[owner] = append(holdings[owner], member.Mint)
+	}
+
+	holders := make([]CollectionHolder, 0, len(holdings))
+	for owner, mints := range holdings {
+		sort.Slice(mints, func(i, j int) bool { return mints[i].ToBase58() < mints[j].ToBase58() })
+		holders = append(holders, CollectionHolder{Owner: owner, Mints: mints})
+	}
+	sort.Slice(holders, func(i, j int) bool { return holders[i].Owner.ToBase58() < holders[j].Owner.ToBase58() })
+	return holders, nil
+}
+
+func (m *Minter) collectionMembersDAS(ctx context.Context, collection common.PublicKey) ([]CollectionMember, error) {
+
+	var members []CollectionMember
+	for page := 1; ; page++ {
+		list, err := m.DAS.GetAssetsByGroup(ctx, "collection", collection.ToBase58(), page, dasPageLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range list.Items {
+			if asset.Burnt {
+				continue
+			}
+			mint, err := ParsePublicKey(asset.ID)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected DAS asset: %w", err)
+			}
+			owner, err := ParsePublicKey(asset.Ownership.Owner)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected owner of DAS asset %v: %w", asset.ID, err)
+			}
+			members = append(members, CollectionMember{Mint: mint, URI: asset.Content.JSONURI, Owner: owner})
+		}
+		if len(list.Items) < dasPageLimit {
+			return members, nil
+		}
+	}
+}
+
+func (m *Minter) collectionMembersProgramAccounts(ctx context.Context, collection common.PublicKey) ([]CollectionMember, error) {
+
+	collectionMetadataAccount, err := token_metadata.GetTokenMetaPubkey(collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection metadata account: %w", err)
+	}
+	accountInfo, err := m.client.GetAccountInfoWithConfig(ctx, collectionMetadataAccount.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection metadata account info: %w", err)
+	}
+	collectionMetadata, err := token_metadata.MetadataDeserialize(accountInfo.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse collection metadata account: %w", err)
+	}
+
+	// the update authority is the only fixed-offset field members share
+	res, err := m.client.RpcClient.GetProgramAccountsWithConfig(ctx, common.MetaplexTokenMetaProgramID.ToBase58(), rpc.GetProgramAccountsConfig{
+		Encoding:   rpc.AccountEncodingBase64,
+		Commitment: m.Commitment,
+		Filters: []rpc.GetProgramAccountsConfigFilter{
+			{MemCmp: &rpc.GetProgramAccountsConfigFilterMemCmp{Offset: 1, Bytes: collectionMetadata.UpdateAuthority.ToBase58()}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata accounts: %w", err)
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to get metadata accounts: %v", res.Error.Message)
+	}
+
+	var members []CollectionMember
+	for _, account := range res.Result {
+		data, err := decodeAccountData(account.Account.Data)
+		if err != nil {
+			return nil, fmt.Errorf("metadata account %v: %w", account.Pubkey, err)
+		}
+		if len(data) == 0 || token_metadata.Key(data[0]) != token_metadata.KeyMetadataV1 {
+			continue
+		}
+		metadata, err := token_metadata.MetadataDeserialize(data)
+		if err != nil || metadata.Collection == nil || !metadata.Collection.Verified || metadata.Collection.Key != collection {
+			continue
+		}
+		members = append(members, CollectionMember{Mint: metadata.Mint, URI: metadata.Data.Uri})
+	}
+	return members, nil
+}
+
+// mintHolder returns the owner of the classic token account holding the one
+// token of mint; held is false once it has been burned.
+func (m *Minter) mintHolder(ctx context.Context, mint common.PublicKey) (owner common.PublicKey, held bool, err error) {
+
+	// only the owner and amount of each token account are read
+	res, err := m.client.RpcClient.GetProgramAccountsWithConfig(ctx, common.TokenProgramID.ToBase58(), rpc.GetProgramAccountsConfig{
+		Encoding:   rpc.AccountEncodingBase64,
+		Commitment: m.Commitment,
+		DataSlice:  &rpc.DataSlice{Offset: 32, Length: 40},
+		Filters: []rpc.GetProgramAccountsConfigFilter{
+			{DataSize: token.TokenAccountSize},
+			{MemCmp: &rpc.GetProgramAccountsConfigFilterMemCmp{Offset: 0, Bytes: mint.ToBase58()}},
+		},
+	})
+	if err != nil {
+		return common.PublicKey{}, false, fmt.Errorf("failed to get token accounts of %v: %w", mint.ToBase58(), err)
+	}
+	if res.Error != nil {
+		return common.PublicKey{}, false, fmt.Errorf("failed to get token accounts of %v: %v", mint.ToBase58(), res.Error.Message)
+	}
+
+	for _, account := range res.Result {
+		data, err := decodeAccountData(account.Account.Data)
+		if err != nil || len(data) != 40 {
+			return common.PublicKey{}, false, fmt.Errorf("token account %v: unexpected data", account.Pubkey)
+		}
+		if binary.LittleEndian.Uint64(data[32:]) == 1 {
+			return common.PublicKeyFromBytes(data[:32]), true, nil
+		}
+	}
+	return common.PublicKey{}, false, nil
+}
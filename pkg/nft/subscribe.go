@@ -0,0 +1,98 @@
+package nft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// waitForSignatureNotification waits for the transaction to reach
+// commitment through a signatureSubscribe subscription on
+// WSEndpoint. A transaction that failed on chain returns ErrTxFailed; any
+// other error means the websocket could not be used.
+func (m *Minter) waitForSignatureNotification(ctx context.Context, txHash string, commitment rpc.Commitment) error {
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, m.WSEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %v: %w", m.WSEndpoint, err)
+	}
+	defer conn.Close()
+
+	// reads block, so closing the connection is what ends them on ctx
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	err = conn.WriteJSON(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "signatureSubscribe",
+		"params":  []any{txHash, map[string]any{"commitment": commitment}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %v: %w", txHash, err)
+	}
+
+	subscribed := false
+	for {
+		var message struct {
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+			Method string `json:"method"`
+			Params struct {
+				Result struct {
+					Value struct {
+						Err any `json:"err"`
+					} `json:"value"`
+				} `json:"result"`
+			} `json:"params"`
+		}
+		if err := conn.ReadJSON(&message); err != nil {
+			return fmt.Errorf("failed to read from %v: %w", m.WSEndpoint, err)
+		}
+
+		switch {
+		case message.Error != nil:
+			return fmt.Errorf("signatureSubscribe failed: %v", message.Error.Message)
+
+		case !subscribed && message.Result != nil:
+			subscribed = true
+			// the transaction may have landed before the subscription, in
+			// which case no notification follows
+			if reached, err := m.signatureStatusReached(ctx, txHash, commitment); reached || err != nil {
+				return err
+			}
+
+		case message.Method == "signatureNotification":
+			if message.Params.Result.Value.Err != nil {
+				return fmt.Errorf("%w: %v", ErrTxFailed, message.Params.Result.Value.Err)
+			}
+			return nil
+		}
+	}
+}
+
+// signatureStatusReached reports whether the transaction has reached
+// commitment; one that failed on chain returns ErrTxFailed. RPC errors
+// report it as not reached yet.
+func (m *Minter) signatureStatusReached(ctx context.Context, txHash string, commitment rpc.Commitment) (bool, error) {
+	statuses, err := m.client.GetSignatureStatuses(ctx, []string{txHash})
+	if err != nil || len(statuses) == 0 || statuses[0] == nil {
+		return false, nil
+	}
+	if statuses[0].Err != nil {
+		return false, fmt.Errorf("%w: %v", ErrTxFailed, statuses[0].Err)
+	}
+	return statuses[0].ConfirmationStatus != nil && commitmentReached(*statuses[0].ConfirmationStatus, commitment), nil
+}
@@ -0,0 +1,138 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/compute_budget"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+var (
+	ErrSwapNotFullySigned = errors.New("swap transaction is missing signatures")
+	ErrSwapExpired        = errors.New("swap transaction has expired")
+)
+
+// SwapRequest exchanges the NFT held in TokenA by PartyA for the NFT held in
+// TokenB by PartyB, in a single transaction.
+type SwapRequest struct {
+	TokenA common.PublicKey
+	PartyA common.PublicKey
+	TokenB common.PublicKey
+	PartyB common.PublicKey
+}
+
+// BuildSwap builds each leg like Transfer, so both parties are screened as
+// recipients and programmable and Token-2022 NFTs move through their own
+// programs, and returns the swap transaction signed by the fee payer only.
+// Each party signs the serialized message and hands back its signature,
+// which is added with tx.AddSignature; the swap expires together with its
+// blockhash.
+func (m *Minter) BuildSwap(ctx context.Context, req SwapRequest) (*types.Transaction, error) {
+
+	feePayer := m.feePayer
+
+	legA, _, err := m.transferInstructions(ctx, TransferRequest{
+		TokenAccount: req.TokenA,
+		Sender:       types.Account{PublicKey: req.PartyA},
+		Receiver:     req.PartyB,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build party A's leg: %w", err)
+	}
+	legB, _, err := m.transferInstructions(ctx, TransferRequest{
+		TokenAccount: req.TokenB,
+		Sender:       types.Account{PublicKey: req.PartyB},
+		Receiver:     req.PartyA,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build party B's leg: %w", err)
+	}
+
+	// a transaction takes one compute unit limit, so the pNFT legs' limits
+	// are added up
+	var units uint32
+	var instructions []types.Instruction
+	for _, instruction := range append(legA, legB...) {
+		if instruction.ProgramID == common.ComputeBudgetProgramID {
+			units += programmableComputeUnits
+			continue
+		}
+		instructions = append(instructions, instruction)
+	}
+	if units > 0 {
+		instructions = append([]types.Instruction{
+			compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+				Units: units,
+			}),
+		}, instructions...)
+	}
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions:    instructions,
+		}),
+		Signers: []types.Account{feePayer},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	return &tx, nil
+}
+
+// SignSwap adds party's signature to a swap built by BuildSwap.
+func SignSwap(tx *types.Transaction, party types.Account) error {
+	message, err := tx.Message.Serialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize message: %w", err)
+	}
+	if err := tx.AddSignature(party.Sign(message)); err != nil {
+		return fmt.Errorf("%v is not a party to the swap: %w", party.PublicKey.ToBase58(), err)
+	}
+	return nil
+}
+
+// SendSwap submits a swap once both parties have signed it.
+func (m *Minter) SendSwap(ctx context.Context, tx *types.Transaction) (txHash string, err error) {
+
+	for _, sig := range tx.Signatures {
+		if isEmptySignature(sig) {
+			return "", ErrSwapNotFullySigned
+		}
+	}
+
+	valid, err := m.client.IsBlockhashValidWithConfig(ctx, tx.Message.RecentBlockHash, client.IsBlockhashValidConfig{Commitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to check blockhash: %w", err)
+	}
+	if !valid {
+		return "", ErrSwapExpired
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, *tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return txSig, nil
+}
+
+func isEmptySignature(sig types.Signature) bool {
+	for _, b := range sig {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package nft
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/near/borsh-go"
+)
+
+// Token-2022 accounts keep the classic layout and append an account type
+// byte plus a TLV list of extensions, starting right after the size of a
+// classic token account (mints are zero-padded up to that size).
+const token2022AccountTypeOffset = token.TokenAccountSize
+
+const (
+	token2022AccountTypeMint    byte = 1
+	token2022AccountTypeAccount byte = 2
+)
+
+type ExtensionType uint16
+
+const (
+	ExtensionUninitialized         ExtensionType = 0
+	ExtensionTransferFeeConfig     ExtensionType = 1
+	ExtensionMintCloseAuthority    ExtensionType = 3
+	ExtensionNonTransferable       ExtensionType = 9
+	ExtensionInterestBearingConfig ExtensionType = 10
+	ExtensionPermanentDelegate     ExtensionType = 12
+	ExtensionTransferHook          ExtensionType = 14
+	ExtensionMetadataPointer       ExtensionType = 18
+	ExtensionTokenMetadata         ExtensionType = 19
+)
+
+type TransferFee struct {
+	Epoch                  uint64
+	MaximumFee             uint64
+	TransferFeeBasisPoints uint16
+}
+
+type TransferFeeConfig struct {
+	TransferFeeConfigAuthority *common.PublicKey
+	WithdrawWithheldAuthority  *common.PublicKey
+	WithheldAmount             uint64
+	OlderTransferFee           TransferFee
+	NewerTransferFee           TransferFee
+}
+
+type InterestBearingConfig struct {
+	RateAuthority *common.PublicKey
+	CurrentRate   int16 // basis points
+}
+
+type TransferHook struct {
+	Authority *common.PublicKey
+	ProgramID *common.PublicKey
+}
+
+type MetadataPointer struct {
+	Authority       *common.PublicKey
+	MetadataAddress *common.PublicKey
+}
+
+type TokenMetadataField struct {
+	Key   string
+	Value string
+}
+
+type TokenMetadata struct {
+	UpdateAuthority    common.PublicKey
+	Mint               common.PublicKey
+	Name               string
+	Symbol             string
+	Uri                string
+	AdditionalMetadata []TokenMetadataField
+}
+
+type MintExtensions struct {
+	TransferFeeConfig  *TransferFeeConfig
+	MintCloseAuthority *common.PublicKey
+	NonTransferable    bool
+	InterestBearing    *InterestBearingConfig
+	PermanentDelegate  *common.PublicKey
+	TransferHook       *TransferHook
+	MetadataPointer    *MetadataPointer
+	TokenMetadata      *TokenMetadata
+	Other              []ExtensionType
+}
+
+// splitToken2022Data returns the classic part of a Token-2022 account and its
+// extension TLV data (nil when the account carries no extensions).
+func splitToken2022Data(data []byte, baseSize int, accountType byte) ([]byte, []byte, error) {
+	if len(data) == baseSize {
+		return data, nil, nil
+	}
+	if len(data) <= token2022AccountTypeOffset {
+		return nil, nil, token.ErrInvalidAccountDataSize
+	}
+	if data[token2022AccountTypeOffset] != accountType {
+		return nil, nil, fmt.Errorf("unexpected token-2022 account type %d", data[token2022AccountTypeOffset])
+	}
+	return data[:baseSize], data[token2022AccountTypeOffset+1:], nil
+}
+
+func parseMintExtensions(tlv []byte) (MintExtensions, error) {
+	var ext MintExtensions
+	for len(tlv) >= 4 {
+		typ := ExtensionType(binary.LittleEndian.Uint16(tlv[0:2]))
+		length := int(binary.LittleEndian.Uint16(tlv[2:4]))
+		if typ == ExtensionUninitialized {
+			break
+		}
+		if len(tlv) < 4+length {
+			return MintExtensions{}, errors.New("truncated token-2022 extension data")
+		}
+		value := tlv[4 : 4+length]
+		tlv = tlv[4+length:]
+
+		switch typ {
+		case ExtensionTransferFeeConfig:
+			if length != 108 {
+				return MintExtensions{}, fmt.Errorf("invalid transfer fee config length %d", length)
+			}
+			ext.TransferFeeConfig = &TransferFeeConfig{
+				TransferFeeConfigAuthority: optionalPubkey(value[0:32]),
+				WithdrawWithheldAuthority:  optionalPubkey(value[32:64]),
+				WithheldAmount:             binary.LittleEndian.Uint64(value[64:72]),
+				OlderTransferFee:           parseTransferFee(value[72:90]),
+				NewerTransferFee:           parseTransferFee(value[90:108]),
+			}
+		case ExtensionMintCloseAuthority:
+			if length != 32 {
+				return MintExtensions{}, fmt.Errorf("invalid mint close authority length %d", length)
+			}
+			ext.MintCloseAuthority = optionalPubkey(value)
+		case ExtensionNonTransferable:
+			ext.NonTransferable = true
+		case ExtensionInterestBearingConfig:
+			if length != 52 {
+				return MintExtensions{}, fmt.Errorf("invalid interest bearing config length %d", length)
+			}
+			ext.InterestBearing = &InterestBearingConfig{
+				RateAuthority: optionalPubkey(value[0:32]),
+				CurrentRate:   int16(binary.LittleEndian.Uint16(value[50:52])),
+			}
+		case ExtensionPermanentDelegate:
+			if length != 32 {
+				return MintExtensions{}, fmt.Errorf("invalid permanent delegate length %d", length)
+			}
+			ext.PermanentDelegate = optionalPubkey(value)
+		case ExtensionTransferHook:
+			if length != 64 {
+				return MintExtensions{}, fmt.Errorf("invalid transfer hook length %d", length)
+			}
+			ext.TransferHook = &TransferHook{
+				Authority: optionalPubkey(value[0:32]),
+				ProgramID: optionalPubkey(value[32:64]),
+			}
+		case ExtensionMetadataPointer:
+			if length != 64 {
+				return MintExtensions{}, fmt.Errorf("invalid metadata pointer length %d", length)
+			}
+			ext.MetadataPointer = &MetadataPointer{
+				Authority:       optionalPubkey(value[0:32]),
+				MetadataAddress: optionalPubkey(value[32:64]),
+			}
+		case ExtensionTokenMetadata:
+			var metadata TokenMetadata
+			if err := borsh.Deserialize(&metadata, value); err != nil {
+				return MintExtensions{}, fmt.Errorf("failed to parse token metadata extension: %w", err)
+			}
+			ext.TokenMetadata = &metadata
+		default:
+			ext.Other = append(ext.Other, typ)
+		}
+	}
+	return ext, nil
+}
+
+func parseTransferFee(b []byte) TransferFee {
+	return TransferFee{
+		Epoch:                  binary.LittleEndian.Uint64(b[0:8]),
+		MaximumFee:             binary.LittleEndian.Uint64(b[8:16]),
+		TransferFeeBasisPoints: binary.LittleEndian.Uint16(b[16:18]),
+	}
+}
+
+// optionalPubkey decodes an OptionalNonZeroPubkey, where all zeroes means none.
+func optionalPubkey(b []byte) *common.PublicKey {
+	key := common.PublicKeyFromBytes(b)
+	if key == (common.PublicKey{}) {
+		return nil
+	}
+	return &key
+}
+
+// Warnings describes extensions that materially affect holders.
+func (e *MintExtensions) Warnings() []string {
+	var warnings []string
+	if e.PermanentDelegate != nil {
+		warnings = append(warnings, fmt.Sprintf("mint has a permanent delegate %v which can transfer or burn this token from any holder", e.PermanentDelegate.ToBase58()))
+	}
+	if fee := e.TransferFeeConfig; fee != nil && (fee.NewerTransferFee.TransferFeeBasisPoints > 0 || fee.OlderTransferFee.TransferFeeBasisPoints > 0) {
+		warnings = append(warnings, fmt.Sprintf("transfers are charged a fee of %v bps (max %v) from epoch %v",
+			fee.NewerTransferFee.TransferFeeBasisPoints, fee.NewerTransferFee.MaximumFee, fee.NewerTransferFee.Epoch))
+	}
+	if e.NonTransferable {
+		warnings = append(warnings, "token is non-transferable")
+	}
+	return warnings
+}
@@ -0,0 +1,255 @@
+package nft
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/associated_token_account"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/near/borsh-go"
+)
+
+// token2022InstructionMetadataPointer prefixes the MetadataPointer extension
+// instructions; Initialize is its sub-instruction 0.
+const token2022InstructionMetadataPointer = 39
+
+// token2022InstructionInitializeNonTransferableMint makes every token of the
+// mint non-transferable.
+const token2022InstructionInitializeNonTransferableMint = 32
+
+// mintExtension is a Token-2022 mint extension initialized before the mint.
+type mintExtension struct {
+	// size is the length of the extension value.
+	size        int
+	instruction types.Instruction
+}
+
+// token2022MintSize is the size of a mint carrying extensions: the classic
+// mint padded to a token account, the account type and one TLV entry per
+// extension.
+func token2022MintSize(extensions []mintExtension) int {
+	size := token2022AccountTypeOffset + 1
+	for _, extension := range extensions {
+		size += 4 + extension.size
+	}
+	return size
+}
+
+// mintToken2022 creates the NFT under Token-2022 with its metadata in the
+// mint itself: a MetadataPointer to the mint and the TokenMetadata extension.
+// With a transfer hook, the mint also gets the TransferHook extension and the
+// hook program its extra accounts; a soulbound mint gets NonTransferable.
+// The mint authority is removed once the token is minted, so the supply
+// stays one.
+func (m *Minter) mintToken2022(ctx context.Context, params nftParams) (*MintResult, error) {
+
+	feePayer := m.feePayer
+	mint, receiver := params.mint, params.receiver
+
+	ata, err := associatedTokenAddress(receiver, mint.PublicKey, common.Token2022ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid ata: %w", err)
+	}
+
+	// an immutable NFT gets no authority over its pointer or metadata
+	var authority common.PublicKey
+	if params.mutable {
+		authority = feePayer.PublicKey
+	}
+
+	extensions := []mintExtension{
+		{
+			size: 64,
+			instruction: types.Instruction{
+				ProgramID: common.Token2022ProgramID,
+				Accounts: []types.AccountMeta{
+					{PubKey: mint.PublicKey, IsSigner: false, IsWritable: true},
+				},
+				Data: append(append([]byte{token2022InstructionMetadataPointer, 0}, authority.Bytes()...), mint.PublicKey.Bytes()...),
+			},
+		},
+	}
+	if params.soulbound {
+		extensions = append(extensions, mintExtension{
+			size: 0,
+			instruction: types.Instruction{
+				ProgramID: common.Token2022ProgramID,
+				Accounts: []types.AccountMeta{
+					{PubKey: mint.PublicKey, IsSigner: false, IsWritable: true},
+				},
+				Data: []byte{token2022InstructionInitializeNonTransferableMint},
+			},
+		})
+	}
+	var hookInstructions []types.Instruction
+	if params.transferHook != (common.PublicKey{}) {
+		extensions = append(extensions, transferHookExtension(mint.PublicKey, authority, params.transferHook))
+		hookInstructions, err = m.initializeExtraAccountMetas(ctx, mint.PublicKey, feePayer.PublicKey, params.transferHook, params.hookAccounts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mintSize := token2022MintSize(extensions)
+
+	// the token metadata extension is appended by the program when it is
+	// initialized, so the account is created without it but funded for it
+	tokenMetadata, err := borsh.Serialize(TokenMetadata{
+		UpdateAuthority: feePayer.PublicKey,
+		Mint:            mint.PublicKey,
+		Name:            params.data.Name,
+		Symbol:          params.data.Symbol,
+		Uri:             params.data.Uri,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize token metadata: %w", err)
+	}
+	mintAccountRent, err := m.client.GetMinimumBalanceForRentExemption(ctx, uint64(mintSize+4+len(tokenMetadata)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint account rent: %w", err)
+	}
+
+	initializeMetadata, err := borsh.Serialize(struct {
+		Name   string
+		Symbol string
+		Uri    string
+	}{
+		Name:   params.data.Name,
+		Symbol: params.data.Symbol,
+		Uri:    params.data.Uri,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize initialize metadata instruction: %w", err)
+	}
+	initializeDiscriminator := tokenMetadataInterfaceDiscriminator("initialize_account")
+
+	recentBlockhashResponse, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	instructions := []types.Instruction{
+		system.CreateAccount(system.CreateAccountParam{
+			From:     feePayer.PublicKey,
+			New:      mint.PublicKey,
+			Owner:    common.Token2022ProgramID,
+			Lamports: mintAccountRent,
+			Space:    uint64(mintSize),
+		}),
+	}
+	for _, extension := range extensions {
+		instructions = append(instructions, extension.instruction)
+	}
+	instructions = append(instructions,
+		onTokenProgram(token.InitializeMint2(token.InitializeMint2Param{
+			Decimals:   0,
+			Mint:       mint.PublicKey,
+			MintAuth:   feePayer.PublicKey,
+			FreezeAuth: &feePayer.PublicKey,
+		}), common.Token2022ProgramID),
+		types.Instruction{
+			ProgramID: common.Token2022ProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: mint.PublicKey, IsSigner: false, IsWritable: true},      // metadata
+				{PubKey: feePayer.PublicKey, IsSigner: false, IsWritable: false}, // update authority
+				{PubKey: mint.PublicKey, IsSigner: false, IsWritable: false},
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // mint authority
+			},
+			Data: append(initializeDiscriminator[:], initializeMetadata...),
+		},
+		createATAIdempotent(feePayer.PublicKey, receiver, mint.PublicKey, ata, common.Token2022ProgramID),
+		onTokenProgram(token.MintTo(token.MintToParam{
+			Mint:   mint.PublicKey,
+			To:     ata,
+			Auth:   feePayer.PublicKey,
+			Amount: 1,
+		}), common.Token2022ProgramID),
+	)
+	instructions = append(instructions, hookInstructions...)
+	instructions = append(instructions,
+		onTokenProgram(token.SetAuthority(token.SetAuthorityParam{
+			Account:  mint.PublicKey,
+			NewAuth:  nil,
+			AuthType: token.AuthorityTypeMintTokens,
+			Auth:     feePayer.PublicKey,
+		}), common.Token2022ProgramID),
+	)
+	if !params.mutable {
+		updateAuthorityDiscriminator := tokenMetadataInterfaceDiscriminator("update_the_authority")
+		instructions = append(instructions, types.Instruction{
+			ProgramID: common.Token2022ProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: mint.PublicKey, IsSigner: false, IsWritable: true},     // metadata
+				{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // update authority
+			},
+			// all zeroes: no new authority
+			Data: append(updateAuthorityDiscriminator[:], make([]byte, 32)...),
+		})
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: []types.Account{mint, feePayer},
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: recentBlockhashResponse.Blockhash,
+			Instructions:    append(instructions, params.extra...),
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new a tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &MintResult{Signature: txSig, Mint: mint.PublicKey, TokenAccount: ata}, nil
+}
+
+// associatedTokenAddress derives the ATA of owner for a mint of either token
+// program; common.FindAssociatedTokenAddress only knows the classic one.
+func associatedTokenAddress(owner, mint, tokenProgram common.PublicKey) (common.PublicKey, error) {
+	ata, _, err := common.FindProgramAddress(
+		[][]byte{owner.Bytes(), tokenProgram.Bytes(), mint.Bytes()},
+		common.SPLAssociatedTokenAccountProgramID,
+	)
+	return ata, err
+}
+
+// createATAIdempotent is associated_token_account.CreateIdempotent for a
+// mint of either token program.
+func createATAIdempotent(funder, owner, mint, ata, tokenProgram common.PublicKey) types.Instruction {
+	instruction := associated_token_account.CreateIdempotent(associated_token_account.CreateIdempotentParam{
+		Funder:                 funder,
+		Owner:                  owner,
+		Mint:                   mint,
+		AssociatedTokenAccount: ata,
+	})
+	for i := range instruction.Accounts {
+		if instruction.Accounts[i].PubKey == common.TokenProgramID {
+			instruction.Accounts[i].PubKey = tokenProgram
+		}
+	}
+	return instruction
+}
+
+// onTokenProgram sends a classic token instruction to tokenProgram;
+// Token-2022 shares the classic instruction layouts.
+func onTokenProgram(instruction types.Instruction, tokenProgram common.PublicKey) types.Instruction {
+	instruction.ProgramID = tokenProgram
+	return instruction
+}
+
+// tokenMetadataInterfaceDiscriminator is the 8-byte prefix of the
+// spl-token-metadata-interface instruction name.
+func tokenMetadataInterfaceDiscriminator(name string) [8]byte {
+	var discriminator [8]byte
+	digest := sha256.Sum256([]byte("spl_token_metadata_interface:" + name))
+	copy(discriminator[:], digest[:8])
+	return discriminator
+}
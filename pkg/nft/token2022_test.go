@@ -0,0 +1,95 @@
+package nft
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/token"
+)
+
+// tlvEntry packs one extension as Token-2022 stores it: type, length, value.
+func tlvEntry(typ ExtensionType, value []byte) []byte {
+	entry := binary.LittleEndian.AppendUint16(nil, uint16(typ))
+	entry = binary.LittleEndian.AppendUint16(entry, uint16(len(value)))
+	return append(entry, value...)
+}
+
+func TestSplitToken2022Data(t *testing.T) {
+	mint := make([]byte, token.MintAccountSize)
+	mint[0] = 7
+
+	base, tlv, err := splitToken2022Data(mint, token.MintAccountSize, token2022AccountTypeMint)
+	if err != nil || len(base) != token.MintAccountSize || tlv != nil {
+		t.Fatalf("classic mint: %v bytes, tlv %v, error %v", len(base), tlv, err)
+	}
+
+	extended := make([]byte, token2022AccountTypeOffset+1)
+	copy(extended, mint)
+	extended[token2022AccountTypeOffset] = token2022AccountTypeMint
+	extended = append(extended, tlvEntry(ExtensionNonTransferable, nil)...)
+	base, tlv, err = splitToken2022Data(extended, token.MintAccountSize, token2022AccountTypeMint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(base) != token.MintAccountSize || base[0] != 7 {
+		t.Errorf("base = %v bytes starting %v", len(base), base[0])
+	}
+	if len(tlv) != 4 || ExtensionType(binary.LittleEndian.Uint16(tlv)) != ExtensionNonTransferable {
+		t.Errorf("tlv = %v", tlv)
+	}
+
+	if _, _, err := splitToken2022Data(extended, token.TokenAccountSize, token2022AccountTypeAccount); err == nil {
+		t.Error("a mint parsed as a token account")
+	}
+	if _, _, err := splitToken2022Data(extended[:token2022AccountTypeOffset], token.MintAccountSize, token2022AccountTypeMint); err == nil {
+		t.Error("data without the account type parsed")
+	}
+}
+
+func TestParseMintExtensions(t *testing.T) {
+	authority := common.PublicKeyFromString("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM")
+	program := common.PublicKeyFromString("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+	metadata := common.PublicKeyFromString("8csJmhaFLM7Ha8k1VYXtYw53eCRn9BbNRfoXD5D8XMWC")
+
+	var tlv []byte
+	tlv = append(tlv, tlvEntry(ExtensionTransferHook, append(authority.Bytes(), program.Bytes()...))...)
+	tlv = append(tlv, tlvEntry(ExtensionNonTransferable, nil)...)
+	tlv = append(tlv, tlvEntry(ExtensionMetadataPointer, append(make([]byte, 32), metadata.Bytes()...))...)
+	tlv = append(tlv, tlvEntry(ExtensionType(25), []byte{1, 2, 3})...)
+	tlv = append(tlv, make([]byte, 8)...) // zero padding ends the list
+
+	ext, err := parseMintExtensions(tlv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.TransferHook == nil || *ext.TransferHook.Authority != authority || *ext.TransferHook.ProgramID != program {
+		t.Errorf("transfer hook = %+v", ext.TransferHook)
+	}
+	if !ext.NonTransferable {
+		t.Error("not non-transferable")
+	}
+	if ext.MetadataPointer == nil || ext.MetadataPointer.Authority != nil || *ext.MetadataPointer.MetadataAddress != metadata {
+		t.Errorf("metadata pointer = %+v", ext.MetadataPointer)
+	}
+	if !slices.Equal(ext.Other, []ExtensionType{25}) {
+		t.Errorf("other = %v", ext.Other)
+	}
+	if ext.PermanentDelegate != nil || ext.TransferFeeConfig != nil || ext.TokenMetadata != nil {
+		t.Errorf("unexpected extensions %+v", ext)
+	}
+
+	for name, tlv := range map[string][]byte{
+		"truncated entry":               tlvEntry(ExtensionTransferHook, make([]byte, 64))[:40],
+		"bad transfer hook length":      tlvEntry(ExtensionTransferHook, make([]byte, 32)),
+		"bad metadata pointer length":   tlvEntry(ExtensionMetadataPointer, make([]byte, 63)),
+		"bad permanent delegate length": tlvEntry(ExtensionPermanentDelegate, make([]byte, 33)),
+		"bad transfer fee length":       tlvEntry(ExtensionTransferFeeConfig, make([]byte, 100)),
+		"bad token metadata":            tlvEntry(ExtensionTokenMetadata, []byte{1, 2}),
+	} {
+		if _, err := parseMintExtensions(tlv); err == nil {
+			t.Errorf("%v: parsed", name)
+		}
+	}
+}
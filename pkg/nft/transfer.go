@@ -0,0 +1,219 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/compute_budget"
+	"github.com/blocto/solana-go-sdk/program/memo"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// ErrNonTransferable is returned for soulbound NFTs, whose mint has the
+// Token-2022 NonTransferable extension.
+var ErrNonTransferable = errors.New("NFT is soulbound and cannot be transferred")
+
+type TransferRequest struct {
+	TokenAccount common.PublicKey
+	Sender       types.Account
+	Receiver     common.PublicKey
+	// Memo, when set, is attached encrypted to the receiver (see DecryptMemo).
+	// Transfer fails with ErrMemoTooLarge when it does not fit in the
+	// transaction.
+	Memo []byte
+}
+
+type TransferResult struct {
+	Signature string
+	Mint      common.PublicKey
+	// TokenAccount is the receiver's associated token account.
+	TokenAccount common.PublicKey
+}
+
+// Transfer moves the NFT held in req.TokenAccount to the receiver's
+// associated token account, creating it if needed. Programmable NFTs are
+// moved with the token metadata Transfer instruction under their rule set,
+// Token-2022 NFTs through the Token-2022 program.
+func (m *Minter) Transfer(ctx context.Context, req TransferRequest) (*TransferResult, error) {
+
+	feePayer := m.feePayer
+
+	instructions, result, err := m.transferInstructions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions:    instructions,
+		}),
+		Signers: []types.Account{feePayer, req.Sender},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new tx: %w", err)
+	}
+	if len(req.Memo) > 0 {
+		if err := checkTransactionSize(tx, len(req.Memo)); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Signature, err = m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return result, nil
+}
+
+// transferInstructions screens the receiver and builds the transfer. Only
+// the sender's public key is used.
+func (m *Minter) transferInstructions(ctx context.Context, req TransferRequest) ([]types.Instruction, *TransferResult, error) {
+
+	feePayer := m.feePayer
+
+	//token account info
+	tokenInfo, err := m.client.GetAccountInfoWithConfig(ctx, req.TokenAccount.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+	tokenProgram := tokenInfo.Owner
+	tokenAccountData := tokenInfo.Data
+	switch tokenProgram {
+	case common.TokenProgramID:
+	case common.Token2022ProgramID:
+		tokenAccountData, _, err = splitToken2022Data(tokenAccountData, token.TokenAccountSize, token2022AccountTypeAccount)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to split token-2022 account data: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("%v is not a token account", req.TokenAccount.ToBase58())
+	}
+	tokenAccount, err := token.TokenAccountFromData(tokenAccountData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse data to a token account: %w", err)
+	}
+	if tokenAccount.Owner != req.Sender.PublicKey || tokenAccount.Amount != 1 {
+		return nil, nil, fmt.Errorf("%v does not hold the NFT in %v", req.Sender.PublicKey.ToBase58(), req.TokenAccount.ToBase58())
+	}
+	mintPubkey := tokenAccount.Mint
+
+	if err := m.Screening.Check(ctx, req.Sender.PublicKey, req.Receiver, mintPubkey); err != nil {
+		return nil, nil, err
+	}
+
+	// Token-2022 NFTs minted by this package keep their metadata in the
+	// mint and cannot be programmable, but may have a transfer hook
+	programmable, ruleSet := false, (*common.PublicKey)(nil)
+	var hookProgram *common.PublicKey
+	if tokenProgram == common.TokenProgramID {
+		metadata, err := m.getMetadata(ctx, mintPubkey)
+		if err != nil {
+			return nil, nil, err
+		}
+		programmable, ruleSet = isProgrammable(metadata)
+	} else {
+		extensions, err := m.getMintExtensions(ctx, mintPubkey)
+		if err != nil {
+			return nil, nil, err
+		}
+		if extensions.NonTransferable {
+			return nil, nil, fmt.Errorf("%v: %w", mintPubkey.ToBase58(), ErrNonTransferable)
+		}
+		if extensions.TransferHook != nil {
+			hookProgram = extensions.TransferHook.ProgramID
+		}
+	}
+
+	// Recipient's ATA (may not exist yet)
+	receiverAta, err := associatedTokenAddress(req.Receiver, mintPubkey, tokenProgram)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find recipient's ATA: %w", err)
+	}
+
+	var instructions []types.Instruction
+	if programmable {
+		// the token metadata program only moves pNFTs out of the ATA
+		senderAta, err := associatedTokenAddress(req.Sender.PublicKey, mintPubkey, tokenProgram)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find sender's ATA: %w", err)
+		}
+		if req.TokenAccount != senderAta {
+			return nil, nil, fmt.Errorf("programmable NFT %v must be moved from the sender's associated token account %v", mintPubkey.ToBase58(), senderAta.ToBase58())
+		}
+		// pNFT token accounts stay frozen, only the token metadata program
+		// can move them
+		instruction, err := programmableTransferInstruction(mintPubkey, req.Sender.PublicKey, req.Receiver, feePayer.PublicKey, ruleSet)
+		if err != nil {
+			return nil, nil, err
+		}
+		instructions = []types.Instruction{
+			compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+				Units: programmableComputeUnits,
+			}),
+			instruction,
+		}
+	} else {
+		transfer := onTokenProgram(token.TransferChecked(token.TransferCheckedParam{
+			From:     req.TokenAccount,
+			To:       receiverAta,
+			Mint:     mintPubkey,
+			Auth:     req.Sender.PublicKey,
+			Signers:  []common.PublicKey{},
+			Amount:   1,
+			Decimals: 0,
+		}), tokenProgram)
+		if hookProgram != nil {
+			// Token-2022 passes these on to the hook
+			accounts, err := m.transferHookAccounts(ctx, *hookProgram, req.TokenAccount, mintPubkey, receiverAta, req.Sender.PublicKey, 1)
+			if err != nil {
+				return nil, nil, err
+			}
+			transfer.Accounts = append(transfer.Accounts, accounts...)
+		}
+		instructions = []types.Instruction{
+			createATAIdempotent(feePayer.PublicKey, req.Receiver, mintPubkey, receiverAta, tokenProgram),
+			transfer,
+		}
+	}
+
+	if len(req.Memo) > 0 {
+		encrypted, err := EncryptMemo(req.Receiver, req.Memo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encrypt memo: %w", err)
+		}
+		instructions = append(instructions, memo.BuildMemo(memo.BuildMemoParam{
+			Memo: []byte(encrypted),
+		}))
+	}
+
+	return instructions, &TransferResult{Mint: mintPubkey, TokenAccount: receiverAta}, nil
+}
+
+// getMintExtensions reads the extensions of a Token-2022 mint.
+func (m *Minter) getMintExtensions(ctx context.Context, mint common.PublicKey) (MintExtensions, error) {
+	mintInfo, err := m.client.GetAccountInfoWithConfig(ctx, mint.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return MintExtensions{}, fmt.Errorf("failed to get mint info: %w", err)
+	}
+	_, extensionData, err := splitToken2022Data(mintInfo.Data, token.MintAccountSize, token2022AccountTypeMint)
+	if err != nil {
+		return MintExtensions{}, fmt.Errorf("failed to split token-2022 mint data: %w", err)
+	}
+	extensions, err := parseMintExtensions(extensionData)
+	if err != nil {
+		return MintExtensions{}, fmt.Errorf("failed to parse mint extensions: %w", err)
+	}
+	return extensions, nil
+}
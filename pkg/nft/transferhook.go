@@ -0,0 +1,254 @@
+package nft
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// token2022InstructionTransferHook prefixes the TransferHook extension
+// instructions; Initialize is its sub-instruction 0.
+const token2022InstructionTransferHook = 36
+
+// extraAccountMetaSize is the packed size of an ExtraAccountMeta.
+const extraAccountMetaSize = 35
+
+// extraAccountMeta is an spl-transfer-hook-interface ExtraAccountMeta: a
+// literal address when discriminator is 0, otherwise a PDA whose seeds are
+// packed in addressConfig.
+type extraAccountMeta struct {
+	discriminator byte
+	addressConfig [32]byte
+	isSigner      bool
+	isWritable    bool
+}
+
+// extraAccountMetasAddress derives the account where the hook program lists
+// the extra accounts of mint's transfers.
+func extraAccountMetasAddress(mint, hookProgram common.PublicKey) (common.PublicKey, error) {
+	address, _, err := common.FindProgramAddress([][]byte{[]byte("extra-account-metas"), mint.Bytes()}, hookProgram)
+	return address, err
+}
+
+// transferHookExtension points the mint's transfers at hookProgram.
+func transferHookExtension(mint, authority, hookProgram common.PublicKey) mintExtension {
+	return mintExtension{
+		size: 64,
+		instruction: types.Instruction{
+			ProgramID: common.Token2022ProgramID,
+			Accounts: []types.AccountMeta{
+				{PubKey: mint, IsSigner: false, IsWritable: true},
+			},
+			Data: append(append([]byte{token2022InstructionTransferHook, 0}, authority.Bytes()...), hookProgram.Bytes()...),
+		},
+	}
+}
+
+// initializeExtraAccountMetas funds the hook's extra account metas account
+// and has the hook program write accounts into it. The mint authority must
+// sign, so it runs before the authority is removed.
+func (m *Minter) initializeExtraAccountMetas(ctx context.Context, mint, mintAuthority, hookProgram common.PublicKey, accounts []types.AccountMeta) ([]types.Instruction, error) {
+
+	address, err := extraAccountMetasAddress(mint, hookProgram)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid extra account metas address: %w", err)
+	}
+
+	// discriminator, TLV length, slice length, then the metas
+	size := 8 + 4 + 4 + extraAccountMetaSize*len(accounts)
+	rent, err := m.client.GetMinimumBalanceForRentExemption(ctx, uint64(size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extra account metas rent: %w", err)
+	}
+
+	discriminator := transferHookInterfaceDiscriminator("initialize-extra-account-metas")
+	data := append(discriminator[:], binary.LittleEndian.AppendUint32(nil, uint32(len(accounts)))...)
+	for _, account := range accounts {
+		data = append(data, 0) // literal address
+		data = append(data, account.PubKey.Bytes()...)
+		data = append(data, boolByte(account.IsSigner), boolByte(account.IsWritable))
+	}
+
+	return []types.Instruction{
+		system.Transfer(system.TransferParam{
+			From:   m.feePayer.PublicKey,
+			To:     address,
+			Amount: rent,
+		}),
+		{
+			ProgramID: hookProgram,
+			Accounts: []types.AccountMeta{
+				{PubKey: address, IsSigner: false, IsWritable: true},
+				{PubKey: mint, IsSigner: false, IsWritable: false},
+				{PubKey: mintAuthority, IsSigner: true, IsWritable: false},
+				{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			},
+			Data: data,
+		},
+	}, nil
+}
+
+// transferHookAccounts resolves what Token-2022 passes on to the mint's
+// transfer hook: the extra accounts listed by the hook program, then the
+// hook program and its extra account metas account.
+func (m *Minter) transferHookAccounts(ctx context.Context, hookProgram, source, mint, destination, owner common.PublicKey, amount uint64) ([]types.AccountMeta, error) {
+
+	address, err := extraAccountMetasAddress(mint, hookProgram)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid extra account metas address: %w", err)
+	}
+	accountInfo, err := m.client.GetAccountInfoWithConfig(ctx, address.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extra account metas: %w", err)
+	}
+	if accountInfo.Owner != hookProgram {
+		return nil, fmt.Errorf("transfer hook %v has no extra account metas for %v", hookProgram.ToBase58(), mint.ToBase58())
+	}
+	metas, err := parseExtraAccountMetas(accountInfo.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the accounts and data of the hook's Execute instruction, which seeds
+	// may refer to by index
+	accounts := []types.AccountMeta{
+		{PubKey: source},
+		{PubKey: mint},
+		{PubKey: destination},
+		{PubKey: owner},
+		{PubKey: address},
+	}
+	executeDiscriminator := transferHookInterfaceDiscriminator("execute")
+	data := binary.LittleEndian.AppendUint64(executeDiscriminator[:], amount)
+
+	// account data seeds read the accounts they name
+	accountData := func(key common.PublicKey) ([]byte, error) {
+		info, err := m.client.GetAccountInfoWithConfig(ctx, key.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account info of %v: %w", key.ToBase58(), err)
+		}
+		return info.Data, nil
+	}
+
+	for _, meta := range metas {
+		key, err := meta.resolve(hookProgram, accounts, data, accountData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve an extra account of transfer hook %v: %w", hookProgram.ToBase58(), err)
+		}
+		accounts = append(accounts, types.AccountMeta{PubKey: key, IsSigner: meta.isSigner, IsWritable: meta.isWritable})
+	}
+
+	extra := append([]types.AccountMeta{}, accounts[5:]...)
+	return append(extra,
+		types.AccountMeta{PubKey: hookProgram, IsSigner: false, IsWritable: false},
+		types.AccountMeta{PubKey: address, IsSigner: false, IsWritable: false},
+	), nil
+}
+
+// parseExtraAccountMetas reads the Execute entry of an extra account metas
+// account: its discriminator, TLV length, slice length and metas.
+func parseExtraAccountMetas(data []byte) ([]extraAccountMeta, error) {
+	executeDiscriminator := transferHookInterfaceDiscriminator("execute")
+	if len(data) < 16 || !bytes.Equal(data[:8], executeDiscriminator[:]) {
+		return nil, errors.New("invalid extra account metas account")
+	}
+	count := int(binary.LittleEndian.Uint32(data[12:16]))
+	data = data[16:]
+	if len(data) < count*extraAccountMetaSize {
+		return nil, errors.New("truncated extra account metas account")
+	}
+
+	metas := make([]extraAccountMeta, count)
+	for i := range metas {
+		packed := data[i*extraAccountMetaSize : (i+1)*extraAccountMetaSize]
+		metas[i].discriminator = packed[0]
+		copy(metas[i].addressConfig[:], packed[1:33])
+		metas[i].isSigner = packed[33] != 0
+		metas[i].isWritable = packed[34] != 0
+	}
+	return metas, nil
+}
+
+// resolve returns the address of the meta given the accounts resolved so far
+// and the Execute instruction data; accountData reads the accounts of
+// account data seeds.
+func (meta extraAccountMeta) resolve(hookProgram common.PublicKey, accounts []types.AccountMeta, data []byte, accountData func(common.PublicKey) ([]byte, error)) (common.PublicKey, error) {
+	switch {
+	case meta.discriminator == 0:
+		return common.PublicKeyFromBytes(meta.addressConfig[:]), nil
+	case meta.discriminator == 1:
+	case meta.discriminator >= 1<<7:
+		index := int(meta.discriminator - 1<<7)
+		if index >= len(accounts) {
+			return common.PublicKey{}, fmt.Errorf("seed program index %v out of range", index)
+		}
+		hookProgram = accounts[index].PubKey
+	default:
+		return common.PublicKey{}, fmt.Errorf("unknown extra account discriminator %v", meta.discriminator)
+	}
+
+	var seeds [][]byte
+	config := meta.addressConfig[:]
+	for len(config) > 0 && config[0] != 0 {
+		var seed []byte
+		switch {
+		case config[0] == 1 && len(config) >= 2 && len(config) >= 2+int(config[1]): // literal
+			seed, config = config[2:2+int(config[1])], config[2+int(config[1]):]
+		case config[0] == 2 && len(config) >= 3: // instruction data
+			start, length := int(config[1]), int(config[2])
+			if start+length > len(data) {
+				return common.PublicKey{}, fmt.Errorf("instruction data seed out of range")
+			}
+			seed, config = data[start:start+length], config[3:]
+		case config[0] == 3 && len(config) >= 2: // account key
+			index := int(config[1])
+			if index >= len(accounts) {
+				return common.PublicKey{}, fmt.Errorf("account key seed index %v out of range", index)
+			}
+			seed, config = accounts[index].PubKey.Bytes(), config[2:]
+		case config[0] == 4 && len(config) >= 4: // account data
+			index, start, length := int(config[1]), int(config[2]), int(config[3])
+			if index >= len(accounts) {
+				return common.PublicKey{}, fmt.Errorf("account data seed index %v out of range", index)
+			}
+			account, err := accountData(accounts[index].PubKey)
+			if err != nil {
+				return common.PublicKey{}, err
+			}
+			if start+length > len(account) {
+				return common.PublicKey{}, fmt.Errorf("account data seed out of range of %v", accounts[index].PubKey.ToBase58())
+			}
+			seed, config = account[start:start+length], config[4:]
+		default:
+			return common.PublicKey{}, fmt.Errorf("unsupported seed type %v", config[0])
+		}
+		seeds = append(seeds, seed)
+	}
+
+	address, _, err := common.FindProgramAddress(seeds, hookProgram)
+	return address, err
+}
+
+// transferHookInterfaceDiscriminator is the 8-byte prefix of the
+// spl-transfer-hook-interface instruction name.
+func transferHookInterfaceDiscriminator(name string) [8]byte {
+	var discriminator [8]byte
+	digest := sha256.Sum256([]byte("spl-transfer-hook-interface:" + name))
+	copy(discriminator[:], digest[:8])
+	return discriminator
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,241 @@
+package nft
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+var (
+	testHookProgram = common.PublicKeyFromString("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+	testSource      = common.PublicKeyFromString("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM")
+	testMint        = common.PublicKeyFromString("8csJmhaFLM7Ha8k1VYXtYw53eCRn9BbNRfoXD5D8XMWC")
+	testDestination = common.PublicKeyFromString("So11111111111111111111111111111111111111112")
+	testOwner       = common.PublicKeyFromString("SysvarRent111111111111111111111111111111111")
+	testLiteral     = common.PublicKeyFromString("Vote111111111111111111111111111111111111111")
+)
+
+// packExtraAccountMetas hand-encodes an ExtraAccountMetaList with the Execute
+// discriminator, as a hook program stores it.
+func packExtraAccountMetas(metas ...[extraAccountMetaSize]byte) []byte {
+	executeDiscriminator := transferHookInterfaceDiscriminator("execute")
+	data := append([]byte{}, executeDiscriminator[:]...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(4+extraAccountMetaSize*len(metas)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(metas)))
+	for _, meta := range metas {
+		data = append(data, meta[:]...)
+	}
+	return data
+}
+
+// packMeta packs one ExtraAccountMeta; config is zero-padded to 32 bytes.
+func packMeta(discriminator byte, config []byte, isSigner, isWritable bool) [extraAccountMetaSize]byte {
+	var packed [extraAccountMetaSize]byte
+	packed[0] = discriminator
+	copy(packed[1:33], config)
+	packed[33] = boolByte(isSigner)
+	packed[34] = boolByte(isWritable)
+	return packed
+}
+
+func mustPDA(t *testing.T, seeds [][]byte, program common.PublicKey) common.PublicKey {
+	t.Helper()
+	address, _, err := common.FindProgramAddress(seeds, program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func TestParseExtraAccountMetas(t *testing.T) {
+	data := packExtraAccountMetas(
+		packMeta(0, testLiteral.Bytes(), false, true),
+		packMeta(1, []byte{1, 3, 'f', 'o', 'o'}, true, false),
+	)
+	metas, err := parseExtraAccountMetas(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metas) != 2 {
+		t.Fatalf("got %v metas, want 2", len(metas))
+	}
+	if metas[0].discriminator != 0 || common.PublicKeyFromBytes(metas[0].addressConfig[:]) != testLiteral || metas[0].isSigner || !metas[0].isWritable {
+		t.Errorf("meta 0 = %+v", metas[0])
+	}
+	if metas[1].discriminator != 1 || metas[1].addressConfig[2] != 'f' || !metas[1].isSigner || metas[1].isWritable {
+		t.Errorf("meta 1 = %+v", metas[1])
+	}
+
+	for name, data := range map[string][]byte{
+		"short":                 data[:10],
+		"wrong discriminator":   append([]byte{0, 0, 0, 0, 0, 0, 0, 0}, data[8:]...),
+		"truncated meta":        data[:len(data)-1],
+		"count beyond the data": binary.LittleEndian.AppendUint32(append([]byte{}, data[:12]...), 3),
+	} {
+		if _, err := parseExtraAccountMetas(data); err == nil {
+			t.Errorf("%v: parsed", name)
+		}
+	}
+}
+
+func TestExtraAccountMetaResolve(t *testing.T) {
+	metasAddress := mustPDA(t, [][]byte{[]byte("extra-account-metas"), testMint.Bytes()}, testHookProgram)
+	accounts := []types.AccountMeta{
+		{PubKey: testSource},
+		{PubKey: testMint},
+		{PubKey: testDestination},
+		{PubKey: testOwner},
+		{PubKey: metasAddress},
+	}
+	executeDiscriminator := transferHookInterfaceDiscriminator("execute")
+	data := binary.LittleEndian.AppendUint64(executeDiscriminator[:], 1)
+
+	// the source token account: mint, then owner
+	sourceData := append(append([]byte{}, testMint.Bytes()...), testOwner.Bytes()...)
+	accountData := func(key common.PublicKey) ([]byte, error) {
+		if key == testSource {
+			return sourceData, nil
+		}
+		return nil, errors.New("account not found")
+	}
+
+	tests := []struct {
+		name          string
+		discriminator byte
+		config        []byte
+		want          func(t *testing.T) common.PublicKey
+		wantErr       bool
+	}{
+		{
+			name:          "literal address",
+			discriminator: 0,
+			config:        testLiteral.Bytes(),
+			want:          func(*testing.T) common.PublicKey { return testLiteral },
+		},
+		{
+			name:          "literal seed",
+			discriminator: 1,
+			config:        []byte{1, 7, 'c', 'o', 'u', 'n', 't', 'e', 'r'},
+			want: func(t *testing.T) common.PublicKey {
+				return mustPDA(t, [][]byte{[]byte("counter")}, testHookProgram)
+			},
+		},
+		{
+			name:          "instruction data seed",
+			discriminator: 1,
+			config:        []byte{2, 8, 8}, // the amount
+			want: func(t *testing.T) common.PublicKey {
+				return mustPDA(t, [][]byte{data[8:16]}, testHookProgram)
+			},
+		},
+		{
+			name:          "account key seed",
+			discriminator: 1,
+			config:        []byte{3, 3}, // the owner
+			want: func(t *testing.T) common.PublicKey {
+				return mustPDA(t, [][]byte{testOwner.Bytes()}, testHookProgram)
+			},
+		},
+		{
+			name:          "account data seed",
+			discriminator: 1,
+			config:        []byte{4, 0, 32, 32}, // the source's owner
+			want: func(t *testing.T) common.PublicKey {
+				return mustPDA(t, [][]byte{testOwner.Bytes()}, testHookProgram)
+			},
+		},
+		{
+			name:          "mixed seeds",
+			discriminator: 1,
+			config:        []byte{1, 4, 'r', 'o', 'l', 'e', 3, 1, 4, 0, 0, 32},
+			want: func(t *testing.T) common.PublicKey {
+				return mustPDA(t, [][]byte{[]byte("role"), testMint.Bytes(), testMint.Bytes()}, testHookProgram)
+			},
+		},
+		{
+			name:          "PDA of another program",
+			discriminator: 1<<7 + 2, // seeded under the destination
+			config:        []byte{3, 1},
+			want: func(t *testing.T) common.PublicKey {
+				return mustPDA(t, [][]byte{testMint.Bytes()}, testDestination)
+			},
+		},
+		{
+			name:          "program index out of range",
+			discriminator: 1<<7 + 5,
+			config:        []byte{3, 1},
+			wantErr:       true,
+		},
+		{
+			name:          "unknown discriminator",
+			discriminator: 2,
+			config:        []byte{3, 1},
+			wantErr:       true,
+		},
+		{
+			name:          "instruction data out of range",
+			discriminator: 1,
+			config:        []byte{2, 12, 8},
+			wantErr:       true,
+		},
+		{
+			name:          "account key out of range",
+			discriminator: 1,
+			config:        []byte{3, 5},
+			wantErr:       true,
+		},
+		{
+			name:          "account data out of range",
+			discriminator: 1,
+			config:        []byte{4, 0, 48, 32},
+			wantErr:       true,
+		},
+		{
+			name:          "account data of a missing account",
+			discriminator: 1,
+			config:        []byte{4, 2, 0, 32},
+			wantErr:       true,
+		},
+		{
+			name:          "unsupported seed type",
+			discriminator: 1,
+			config:        []byte{9, 0},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metas, err := parseExtraAccountMetas(packExtraAccountMetas(packMeta(tt.discriminator, tt.config, false, false)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := metas[0].resolve(testHookProgram, accounts, data, accountData)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolved to %v, want an error", got.ToBase58())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.want(t); got != want {
+				t.Errorf("got %v, want %v", got.ToBase58(), want.ToBase58())
+			}
+		})
+	}
+}
+
+func TestExtraAccountMetasAddress(t *testing.T) {
+	got, err := extraAccountMetasAddress(testMint, testHookProgram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustPDA(t, [][]byte{[]byte("extra-account-metas"), testMint.Bytes()}, testHookProgram)
+	if got != want {
+		t.Errorf("got %v, want %v", got.ToBase58(), want.ToBase58())
+	}
+}
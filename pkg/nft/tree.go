@@ -0,0 +1,148 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/near/borsh-go"
+)
+
+// treeSizes lists the max depth and max buffer size pairs
+// spl-account-compression accepts.
+var treeSizes = map[[2]uint32]bool{
+	{3, 8}: true, {5, 8}: true,
+	{14, 64}: true, {14, 256}: true, {14, 1024}: true, {14, 2048}: true,
+	{15, 64}: true, {16, 64}: true, {17, 64}: true, {18, 64}: true, {19, 64}: true,
+	{20, 64}: true, {20, 256}: true, {20, 1024}: true, {20, 2048}: true,
+	{24, 64}: true, {24, 256}: true, {24, 512}: true, {24, 1024}: true, {24, 2048}: true,
+	{26, 512}: true, {26, 1024}: true, {26, 2048}: true,
+	{30, 512}: true, {30, 1024}: true, {30, 2048}: true,
+}
+
+// maxCanopyDepth keeps the canopy within what a single account can hold.
+const maxCanopyDepth = 17
+
+type TreeRequest struct {
+	// MaxDepth sets the capacity of the tree to 2^MaxDepth NFTs.
+	MaxDepth uint32
+	// MaxBufferSize is how many concurrent changes the tree accepts per
+	// slot.
+	MaxBufferSize uint32
+	// CanopyDepth caches the top levels of the tree on chain so transfers
+	// pass shorter proofs, at the cost of rent.
+	CanopyDepth int
+	// Public lets anyone mint into the tree, not only the fee payer.
+	Public bool
+}
+
+type TreeResult struct {
+	Signature string
+	Tree      common.PublicKey
+	// Rent is the lamports locked in the tree account.
+	Rent uint64
+}
+
+// TreeRent returns the size and rent of the account of a tree shaped by req.
+func (m *Minter) TreeRent(ctx context.Context, req TreeRequest) (int, uint64, error) {
+	if err := validateTree(req); err != nil {
+		return 0, 0, err
+	}
+	size := merkleTreeSize(req.MaxDepth, req.MaxBufferSize, req.CanopyDepth)
+	rent, err := m.client.GetMinimumBalanceForRentExemption(ctx, uint64(size))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get tree account rent: %w", err)
+	}
+	return size, rent, nil
+}
+
+// CreateTree allocates a concurrent Merkle tree and initializes it with
+// Bubblegum create_tree, making the fee payer its creator. Compressed NFTs
+// are minted into it with MintCompressed.
+func (m *Minter) CreateTree(ctx context.Context, req TreeRequest) (*TreeResult, error) {
+
+	feePayer := m.feePayer
+
+	size, rent, err := m.TreeRent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := types.NewAccount()
+	treeAuthority, _, err := common.FindProgramAddress([][]byte{tree.PublicKey.Bytes()}, BubblegumProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a valid tree config: %w", err)
+	}
+
+	args, err := borsh.Serialize(struct {
+		MaxDepth      uint32
+		MaxBufferSize uint32
+		Public        *bool
+	}{
+		MaxDepth:      req.MaxDepth,
+		MaxBufferSize: req.MaxBufferSize,
+		Public:        &req.Public,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize create tree args: %w", err)
+	}
+	discriminator := anchorDiscriminator("create_tree")
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: []types.Account{tree, feePayer},
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions: []types.Instruction{
+				system.CreateAccount(system.CreateAccountParam{
+					From:     feePayer.PublicKey,
+					New:      tree.PublicKey,
+					Owner:    SPLAccountCompressionProgramID,
+					Lamports: rent,
+					Space:    uint64(size),
+				}),
+				{
+					ProgramID: BubblegumProgramID,
+					Accounts: []types.AccountMeta{
+						{PubKey: treeAuthority, IsSigner: false, IsWritable: true},
+						{PubKey: tree.PublicKey, IsSigner: false, IsWritable: true},
+						{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: true},  // payer
+						{PubKey: feePayer.PublicKey, IsSigner: true, IsWritable: false}, // tree creator
+						{PubKey: SPLNoopProgramID, IsSigner: false, IsWritable: false},
+						{PubKey: SPLAccountCompressionProgramID, IsSigner: false, IsWritable: false},
+						{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+					},
+					Data: append(discriminator[:], args...),
+				},
+			},
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to new a tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	return &TreeResult{Signature: txSig, Tree: tree.PublicKey, Rent: rent}, nil
+}
+
+func validateTree(req TreeRequest) error {
+	if !treeSizes[[2]uint32{req.MaxDepth, req.MaxBufferSize}] {
+		return fmt.Errorf("max depth %v with max buffer size %v is not a supported tree size", req.MaxDepth, req.MaxBufferSize)
+	}
+	if req.CanopyDepth < 0 || req.CanopyDepth > maxCanopyDepth || req.CanopyDepth >= int(req.MaxDepth) {
+		return fmt.Errorf("canopy depth %v must be below the max depth %v and at most %v", req.CanopyDepth, req.MaxDepth, maxCanopyDepth)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package nft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/pkg/pointer"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+var ErrImmutableMetadata = errors.New("metadata is immutable")
+
+// UpdateRequest changes the metadata of a mutable NFT. Nil fields keep their
+// current value.
+type UpdateRequest struct {
+	Mint common.PublicKey
+	// UpdateAuthority signs the update; the zero account means the fee payer.
+	UpdateAuthority types.Account
+
+	Name                 *string
+	URI                  *string
+	Creators             *[]token_metadata.Creator
+	SellerFeeBasisPoints *uint16
+	// Collection moves the NFT to another, unverified collection. The zero
+	// key removes it from its collection.
+	Collection *common.PublicKey
+
+	// NewUpdateAuthority hands the update authority to another key.
+	NewUpdateAuthority *common.PublicKey
+
+	// MakeImmutable locks the metadata for good once this update lands.
+	MakeImmutable bool
+}
+
+// UpdateNFT rewrites the NFT's metadata with UpdateMetadataAccountV2 and,
+// when History is set, records the old and new metadata. A failure to record
+// is returned along with the signature of the update already sent.
+func (m *Minter) UpdateNFT(ctx context.Context, req UpdateRequest) (string, error) {
+
+	feePayer := m.feePayer
+	authority := req.UpdateAuthority
+	if authority.PublicKey == (common.PublicKey{}) {
+		authority = feePayer
+	}
+
+	instruction, old, updated, err := m.updateInstruction(ctx, req, authority.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions:    []types.Instruction{instruction},
+		}),
+		Signers: []types.Account{feePayer, authority},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to send tx: %w", err)
+	}
+
+	if m.History != nil {
+		if err := m.recordUpdate(ctx, req.Mint, txSig, old, updated); err != nil {
+			return txSig, fmt.Errorf("update %v sent but not recorded: %w", txSig, err)
+		}
+	}
+	return txSig, nil
+}
+
+// updateInstruction merges req into the current metadata and builds the
+// update signed by authority. It also returns the metadata before and after.
+func (m *Minter) updateInstruction(ctx context.Context, req UpdateRequest, authorityPubkey common.PublicKey) (types.Instruction, MetadataSnapshot, MetadataSnapshot, error) {
+
+	current, err := m.getMetadata(ctx, req.Mint)
+	if err != nil {
+		return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, err
+	}
+	if !current.IsMutable {
+		return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, fmt.Errorf("%w: %v", ErrImmutableMetadata, req.Mint.ToBase58())
+	}
+	if current.UpdateAuthority != authorityPubkey {
+		return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, fmt.Errorf("%v is not the update authority of %v", authorityPubkey.ToBase58(), req.Mint.ToBase58())
+	}
+
+	// on-chain strings are padded with zero bytes to their maximum length
+	data := token_metadata.DataV2{
+		Name:                 strings.TrimRight(current.Data.Name, "\x00"),
+		Symbol:               strings.TrimRight(current.Data.Symbol, "\x00"),
+		Uri:                  strings.TrimRight(current.Data.Uri, "\x00"),
+		SellerFeeBasisPoints: current.Data.SellerFeeBasisPoints,
+		Creators:             current.Data.Creators,
+		Collection:           current.Collection,
+		Uses:                 current.Uses,
+	}
+	old := newSnapshot(data)
+	if req.Name != nil {
+		data.Name = NormalizeName(*req.Name)
+	}
+	if req.URI != nil {
+		data.Uri = *req.URI
+	}
+	if _, _, err := fitMetadataStrings(data.Name, data.Symbol, data.Uri, RejectLongNames); err != nil {
+		return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, err
+	}
+	if req.SellerFeeBasisPoints != nil {
+		data.SellerFeeBasisPoints = *req.SellerFeeBasisPoints
+	}
+
+	if req.Creators != nil {
+		// a creator may only turn verified by signing, which here is the
+		// update authority; creators verified before keep their flag
+		wasVerified := map[common.PublicKey]bool{}
+		if current.Data.Creators != nil {
+			for _, creator := range *current.Data.Creators {
+				wasVerified[creator.Address] = creator.Verified
+			}
+		}
+		for _, creator := range *req.Creators {
+			if creator.Verified && !wasVerified[creator.Address] && creator.Address != authorityPubkey {
+				return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, fmt.Errorf("creator %v cannot be verified by this update, only the update authority signs it", creator.Address.ToBase58())
+			}
+		}
+
+		data.Creators = nil
+		if len(*req.Creators) > 0 {
+			data.Creators = req.Creators
+		}
+	}
+	var creators []token_metadata.Creator
+	if data.Creators != nil {
+		creators = *data.Creators
+	}
+	if err := validateRoyalties(creators, data.SellerFeeBasisPoints); err != nil {
+		return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, err
+	}
+
+	if req.Collection != nil && (current.Collection == nil || current.Collection.Key != *req.Collection) {
+		if current.Collection != nil && current.Collection.Verified {
+			return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, fmt.Errorf("%v is a verified member of %v, unverify it before changing its collection", req.Mint.ToBase58(), current.Collection.Key.ToBase58())
+		}
+		data.Collection = nil
+		if *req.Collection != (common.PublicKey{}) {
+			data.Collection = &token_metadata.Collection{Verified: false, Key: *req.Collection}
+		}
+	}
+
+	metadataAccount, err := token_metadata.GetTokenMetaPubkey(req.Mint)
+	if err != nil {
+		return types.Instruction{}, MetadataSnapshot{}, MetadataSnapshot{}, fmt.Errorf("failed to get metadata account: %w", err)
+	}
+
+	var isMutable *bool
+	if req.MakeImmutable {
+		isMutable = pointer.Get(false)
+	}
+
+	return token_metadata.UpdateMetadataAccountV2(token_metadata.UpdateMetadataAccountV2Param{
+		MetadataAccount:    metadataAccount,
+		UpdateAuthority:    authorityPubkey,
+		Data:               &data,
+		NewUpdateAuthority: req.NewUpdateAuthority,
+		IsMutable:          isMutable,
+	}), old, newSnapshot(data), nil
+}
@@ -0,0 +1,191 @@
+package nft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/near/borsh-go"
+)
+
+type VerifyCollectionRequest struct {
+	// Mint is the item NFT whose metadata names Collection.
+	Mint       common.PublicKey
+	Collection common.PublicKey
+	// Authority is the update authority of the collection NFT.
+	Authority types.Account
+}
+
+// VerifyCollectionItem marks an already minted item as a verified member of
+// its collection. The fee payer pays, the collection authority signs.
+func (m *Minter) VerifyCollectionItem(ctx context.Context, req VerifyCollectionRequest) (string, error) {
+
+	feePayer := m.feePayer
+
+	instruction, err := m.verifyCollectionInstruction(ctx, req.Mint, req.Collection, req.Authority.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := m.client.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{Commitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: m.newMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: res.Blockhash,
+			Instructions:    []types.Instruction{instruction},
+		}),
+		Signers: []types.Account{feePayer, req.Authority},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to new tx: %w", err)
+	}
+
+	txSig, err := m.client.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{PreflightCommitment: m.Commitment})
+	if err != nil {
+		return "", fmt.Errorf("failed to send tx: %w", err)
+	}
+	return txSig, nil
+}
+
+// verifyCollectionInstruction builds VerifySizedCollectionItem for sized
+// collections and VerifyCollection for legacy ones, after checking that
+// authority may verify items of the collection.
+func (m *Minter) verifyCollectionInstruction(ctx context.Context, mint, collectionMint, authority common.PublicKey) (types.Instruction, error) {
+
+	collection, err := m.collectionMetadata(ctx, collectionMint, authority)
+	if err != nil {
+		return types.Instruction{}, err
+	}
+
+	metadata, err := token_metadata.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	collectionMetadata, err := token_metadata.GetTokenMetaPubkey(collectionMint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid collection metadata: %w", err)
+	}
+	collectionMasterEdition, err := token_metadata.GetMasterEdition(collectionMint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid collection master edition: %w", err)
+	}
+
+	// a sized collection counts its verified items, so its metadata is
+	// written and the authority is not
+	sized := collection.CollectionDetails != nil
+	instruction := token_metadata.InstructionVerifyCollection
+	if sized {
+		instruction = token_metadata.InstructionVerifySizedCollectionItem
+	}
+
+	data, err := borsh.Serialize(struct {
+		Instruction token_metadata.Instruction
+	}{
+		Instruction: instruction,
+	})
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to serialize instruction: %w", err)
+	}
+
+	return types.Instruction{
+		ProgramID: common.MetaplexTokenMetaProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: metadata, IsSigner: false, IsWritable: true},
+			{PubKey: authority, IsSigner: true, IsWritable: !sized},
+			{PubKey: m.feePayer.PublicKey, IsSigner: true, IsWritable: true},
+			{PubKey: collectionMint, IsSigner: false, IsWritable: false},
+			{PubKey: collectionMetadata, IsSigner: false, IsWritable: sized},
+			{PubKey: collectionMasterEdition, IsSigner: false, IsWritable: false},
+		},
+		Data: data,
+	}, nil
+}
+
+// verifyCollectionV1Instruction builds the token metadata Verify
+// instruction, which programmable NFTs need in place of the legacy verify
+// instructions.
+func (m *Minter) verifyCollectionV1Instruction(ctx context.Context, mint, collectionMint, authority common.PublicKey) (types.Instruction, error) {
+
+	if _, err := m.collectionMetadata(ctx, collectionMint, authority); err != nil {
+		return types.Instruction{}, err
+	}
+
+	metadata, err := token_metadata.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid token metadata: %w", err)
+	}
+	collectionMetadata, err := token_metadata.GetTokenMetaPubkey(collectionMint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid collection metadata: %w", err)
+	}
+	collectionMasterEdition, err := token_metadata.GetMasterEdition(collectionMint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to find a valid collection master edition: %w", err)
+	}
+
+	data, err := borsh.Serialize(struct {
+		Instruction      token_metadata.Instruction
+		VerificationArgs uint8
+	}{
+		Instruction:      token_metadata.InstructionVerify,
+		VerificationArgs: 1, // CollectionV1
+	})
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to serialize instruction: %w", err)
+	}
+
+	return types.Instruction{
+		ProgramID: common.MetaplexTokenMetaProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: authority, IsSigner: true, IsWritable: false},
+			{PubKey: omittedAccount, IsSigner: false, IsWritable: false}, // delegate record
+			{PubKey: metadata, IsSigner: false, IsWritable: true},
+			{PubKey: collectionMint, IsSigner: false, IsWritable: false},
+			{PubKey: collectionMetadata, IsSigner: false, IsWritable: true},
+			{PubKey: collectionMasterEdition, IsSigner: false, IsWritable: false},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+		},
+		Data: data,
+	}, nil
+}
+
+// collectionMetadata reads the collection's metadata and checks that
+// authority may verify its items.
+func (m *Minter) collectionMetadata(ctx context.Context, collectionMint, authority common.PublicKey) (token_metadata.Metadata, error) {
+	collection, err := m.getMetadata(ctx, collectionMint)
+	if err != nil {
+		return token_metadata.Metadata{}, fmt.Errorf("failed to get collection metadata: %w", err)
+	}
+	if collection.UpdateAuthority != authority {
+		return token_metadata.Metadata{}, fmt.Errorf("%v is not the update authority of collection %v", authority.ToBase58(), collectionMint.ToBase58())
+	}
+	return collection, nil
+}
+
+// getMetadata reads and parses the Metaplex metadata account of mint.
+func (m *Minter) getMetadata(ctx context.Context, mint common.PublicKey) (token_metadata.Metadata, error) {
+
+	metadataAccount, err := token_metadata.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return token_metadata.Metadata{}, fmt.Errorf("failed to get metadata account: %w", err)
+	}
+
+	accountInfo, err := m.client.GetAccountInfoWithConfig(ctx, metadataAccount.ToBase58(), client.GetAccountInfoConfig{Commitment: m.Commitment})
+	if err != nil {
+		return token_metadata.Metadata{}, fmt.Errorf("failed to get metadata account info: %w", err)
+	}
+
+	metadata, err := token_metadata.MetadataDeserialize(accountInfo.Data)
+	if err != nil {
+		return token_metadata.Metadata{}, fmt.Errorf("failed to parse metadata account: %w", err)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"XChenLabs/solana-nft-demo/pkg/metadata"
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// fetchRarityItems fetches the metadata JSON of every member, concurrency
+// at a time. Members whose JSON cannot be fetched are logged and left out.
+func fetchRarityItems(ctx context.Context, members []nft.CollectionMember, concurrency int) []metadata.RarityItem {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	fetched := make([]*metadata.RarityItem, len(members))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				md, err := metadata.Fetch(ctx, members[i].URI)
+				if err != nil {
+					log.Printf("WARNING: leaving %v out of the ranking: %v", members[i].Mint.ToBase58(), err)
+					continue
+				}
+				fetched[i] = &metadata.RarityItem{Mint: members[i].Mint.ToBase58(), Attributes: md.Attributes}
+			}
+		}()
+	}
+	for i := range members {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	items := make([]metadata.RarityItem, 0, len(members))
+	for _, item := range fetched {
+		if item != nil {
+			items = append(items, *item)
+		}
+	}
+	return items
+}
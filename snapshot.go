@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"XChenLabs/solana-nft-demo/pkg/nft"
+)
+
+// snapshotHolder is one wallet of a holder snapshot.
+type snapshotHolder struct {
+	Owner string   `json:"owner"`
+	Mints []string `json:"mints"`
+}
+
+// parseSnapshotSource parses the -source flag of snapshot and rarity.
+func parseSnapshotSource(s string) (nft.SnapshotSource, error) {
+	switch s {
+	case "auto":
+		return nft.SnapshotAuto, nil
+	case "das":
+		return nft.SnapshotDAS, nil
+	case "rpc":
+		return nft.SnapshotProgramAccounts, nil
+	default:
+		return 0, fmt.Errorf("-source must be auto, das or rpc, not %q", s)
+	}
+}
+
+// writeSnapshot writes holders to path, or to stdout when path is "" or
+// "-": as CSV rows of owner, mint when path ends in .csv, and as a JSON
+// array of owners with their mints otherwise.
+func writeSnapshot(path string, holders []nft.CollectionHolder) error {
+	w := io.Writer(os.Stdout)
+	if path != "" && path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = writeCSVSnapshot(w, holders)
+	} else {
+		err = writeJSONSnapshot(w, holders)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+func writeJSONSnapshot(w io.Writer, holders []nft.CollectionHolder) error {
+	out := make([]snapshotHolder, 0, len(holders))
+	for _, holder := range holders {
+		entry := snapshotHolder{Owner: holder.Owner.ToBase58()}
+		for _, mint := range holder.Mints {
+			entry.Mints = append(entry.Mints, mint.ToBase58())
+		}
+		out = append(out, entry)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeCSVSnapshot(w io.Writer, holders []nft.CollectionHolder) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"owner", "mint"}); err != nil {
+		return err
+	}
+	for _, holder := range holders {
+		for _, mint := range holder.Mints {
+			if err := cw.Write([]string{holder.Owner.ToBase58(), mint.ToBase58()}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}